@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/mdlayher/yamlnetlink"
+)
+
+// diffMain implements the diff subcommand, which reports the compatibility of
+// changes between two versions of a YAML netlink specification. It exits with
+// status 1 if any change is breaking.
+func diffMain(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "optional: output changes as a JSON array")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		log.Fatal("must specify old and new YAML netlink files:\n$ yamlnetlink-go diff old.yaml new.yaml")
+	}
+
+	var specs []*yamlnetlink.Spec
+	for _, path := range fs.Args() {
+		s, err := yamlnetlink.ParseFile(path, nil)
+		if err != nil {
+			log.Fatalf("failed to parse YAML netlink file %q: %v", path, err)
+		}
+
+		specs = append(specs, s)
+	}
+
+	cs := yamlnetlink.Diff(specs[0], specs[1])
+
+	if *jsonFlag {
+		if cs == nil {
+			// Always produce an array.
+			cs = yamlnetlink.Changes{}
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(cs); err != nil {
+			log.Fatalf("failed to encode changes: %v", err)
+		}
+	} else {
+		for _, c := range cs {
+			fmt.Println(c)
+		}
+	}
+
+	if cs.Breaking() {
+		os.Exit(1)
+	}
+}
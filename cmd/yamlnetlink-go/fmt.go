@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/mdlayher/yamlnetlink"
+)
+
+// fmtMain implements the fmt subcommand, which rewrites YAML netlink
+// specifications in canonical form.
+func fmtMain(args []string) {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	wFlag := fs.Bool("w", false, "optional: write the result to the source file instead of stdout")
+	_ = fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		log.Fatal("must specify one or more YAML netlink files:\n$ yamlnetlink-go fmt -w nlctrl.yaml")
+	}
+
+	for _, path := range fs.Args() {
+		in, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("failed to read file: %v", err)
+		}
+
+		// Keys which are not modeled by Spec would be lost when formatting,
+		// so refuse to format such files.
+		s, err := yamlnetlink.ParseWithOptions(bytes.NewReader(in), &yamlnetlink.ParseOptions{Strict: true})
+		if err != nil {
+			log.Fatalf("failed to parse YAML netlink file %q: %v", path, err)
+		}
+
+		out, err := yamlnetlink.Marshal(s)
+		if err != nil {
+			log.Fatalf("failed to format YAML netlink file %q: %v", path, err)
+		}
+
+		if !*wFlag {
+			_, _ = os.Stdout.Write(out)
+			continue
+		}
+
+		if bytes.Equal(in, out) {
+			continue
+		}
+
+		if err := os.WriteFile(path, out, 0o644); err != nil {
+			log.Fatalf("failed to write file: %v", err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/mdlayher/yamlnetlink"
+)
+
+// fromHeaderMain implements the from-header subcommand, which generates a
+// skeleton YAML netlink specification from the enums of a C UAPI header.
+func fromHeaderMain(args []string) {
+	fs := flag.NewFlagSet("from-header", flag.ExitOnError)
+	nameFlag := fs.String("name", "", "optional: specify the family name (default: use the header's *_GENL_NAME macro)")
+	headerFlag := fs.String("uapi-header", "", "optional: specify the header's include path, such as linux/ethtool_netlink.h")
+	_ = fs.Parse(args)
+
+	path := fs.Arg(0)
+	if path == "" {
+		log.Fatal("must specify a C header file:\n$ yamlnetlink-go from-header -uapi-header linux/foo.h foo.h")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	s, err := yamlnetlink.ParseHeader(f, &yamlnetlink.HeaderOptions{
+		Name:       *nameFlag,
+		UAPIHeader: *headerFlag,
+	})
+	if err != nil {
+		// Enums which cannot be evaluated are omitted from the skeleton, which
+		// is still useful as a starting point.
+		var herrs yamlnetlink.HeaderEnumErrors
+		if !errors.As(err, &herrs) {
+			log.Fatalf("failed to parse C header %q: %v", path, err)
+		}
+
+		for _, e := range herrs {
+			log.Printf("warning: %v", e)
+		}
+	}
+
+	out, err := yamlnetlink.Marshal(s)
+	if err != nil {
+		log.Fatalf("failed to marshal YAML netlink spec: %v", err)
+	}
+
+	_, _ = os.Stdout.Write(out)
+}
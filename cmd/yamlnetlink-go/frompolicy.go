@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/yamlnetlink"
+	"github.com/mdlayher/yamlnetlink/introspect"
+)
+
+// fromPolicyMain implements the from-policy subcommand, which generates a
+// draft YAML netlink specification from the running kernel's attribute policy
+// for a generic netlink family.
+func fromPolicyMain(args []string) {
+	fs := flag.NewFlagSet("from-policy", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	family := fs.Arg(0)
+	if family == "" {
+		log.Fatal("must specify a generic netlink family:\n$ yamlnetlink-go from-policy nlctrl")
+	}
+
+	c, err := genetlink.Dial(nil)
+	if err != nil {
+		log.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	s, err := introspect.Policy(c, family)
+	if err != nil {
+		log.Fatalf("failed to dump policy for family %q: %v", family, err)
+	}
+
+	out, err := yamlnetlink.Marshal(s)
+	if err != nil {
+		log.Fatalf("failed to marshal YAML netlink spec: %v", err)
+	}
+
+	_, _ = os.Stdout.Write(out)
+}
@@ -1,10 +1,34 @@
 // Command yamlnetlink-go generates Go code from YAML netlink specifications.
+//
+// The fmt subcommand rewrites YAML netlink specifications in canonical form:
+//
+//	$ yamlnetlink-go fmt -w nlctrl.yaml
+//
+// The diff subcommand reports whether the changes between two versions of a
+// YAML netlink specification are compatible or breaking, and exits with status
+// 1 if any change is breaking:
+//
+//	$ yamlnetlink-go diff -json old.yaml new.yaml
+//
+// The from-header subcommand generates a skeleton YAML netlink specification
+// from the enums of a C UAPI header, whose attribute types must then be filled
+// in by hand:
+//
+//	$ yamlnetlink-go from-header -uapi-header linux/foo.h foo.h > foo.yaml
+//
+// The from-policy subcommand generates a draft YAML netlink specification from
+// the attribute policy which the running kernel reports for a generic netlink
+// family:
+//
+//	$ yamlnetlink-go from-policy nlctrl > nlctrl.yaml
 package main
 
 import (
+	"errors"
 	"flag"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/mdlayher/yamlnetlink"
 )
@@ -12,7 +36,30 @@ import (
 func main() {
 	log.SetFlags(0)
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fmt":
+			fmtMain(os.Args[2:])
+			return
+		case "diff":
+			diffMain(os.Args[2:])
+			return
+		case "from-header":
+			fromHeaderMain(os.Args[2:])
+			return
+		case "from-policy":
+			fromPolicyMain(os.Args[2:])
+			return
+		}
+	}
+
 	pFlag := flag.String("p", "", "optional: specify a package name for the generated code (default: use YAML netlink spec name)")
+	strictFlag := flag.Bool("strict", false, "optional: fail if the YAML netlink spec contains unknown keys")
+	schemaFlag := flag.Bool("schema", false, "optional: validate the YAML netlink spec against the upstream JSON schema for its protocol")
+	unixFlag := flag.Bool("unix", false, "optional: refer to attribute type and command constants in golang.org/x/sys/unix rather than generating them")
+
+	var overlays stringsFlag
+	flag.Var(&overlays, "overlay", "optional: apply a YAML overlay file to the YAML netlink spec before generation; may be repeated")
 	flag.Parse()
 
 	path := flag.Arg(0)
@@ -20,22 +67,78 @@ func main() {
 		log.Fatal("must specify a YAML netlink file:\n$ yamlnetlink-go nlctrl.yaml")
 	}
 
-	f, err := os.Open(path)
+	s, err := yamlnetlink.ParseFile(path, &yamlnetlink.ParseOptions{Strict: *strictFlag})
 	if err != nil {
-		log.Fatalf("failed to open file: %v", err)
+		log.Fatalf("failed to parse YAML netlink file: %v", err)
 	}
-	defer f.Close()
 
-	s, err := yamlnetlink.Parse(f)
-	if err != nil {
-		log.Fatalf("failed to parse YAML netlink file: %v", err)
+	if len(overlays) > 0 {
+		s, err = applyOverlays(s, overlays)
+		if err != nil {
+			log.Fatalf("failed to apply overlays: %v", err)
+		}
 	}
-	_ = f.Close()
 
-	code, err := yamlnetlink.Generate(s, &yamlnetlink.Config{Package: *pFlag})
+	if *schemaFlag {
+		if err := s.ValidateSchema(); err != nil {
+			log.Fatalf("YAML netlink file does not match schema:\n%v", err)
+		}
+	}
+
+	// Generate validates the spec before generating any code.
+	code, err := yamlnetlink.Generate(s, &yamlnetlink.Config{
+		Package: *pFlag,
+		UseUnix: *unixFlag,
+	})
 	if err != nil {
+		var verrs yamlnetlink.ValidationErrors
+		if errors.As(err, &verrs) {
+			log.Fatalf("invalid YAML netlink file:\n%v", err)
+		}
+
 		log.Fatalf("failed to generate code: %v", err)
 	}
 
+	for _, e := range s.Unresolved() {
+		log.Printf("warning: %v", e)
+	}
+
 	_, _ = os.Stdout.Write(code)
 }
+
+// applyOverlays parses the overlay files at paths and applies them to s in
+// order.
+func applyOverlays(s *yamlnetlink.Spec, paths []string) (*yamlnetlink.Spec, error) {
+	ovs := make([]*yamlnetlink.Overlay, 0, len(paths))
+	for _, p := range paths {
+		o, err := parseOverlay(p)
+		if err != nil {
+			return nil, err
+		}
+
+		ovs = append(ovs, o)
+	}
+
+	return s.Apply(ovs...)
+}
+
+// parseOverlay parses the overlay file at path.
+func parseOverlay(path string) (*yamlnetlink.Overlay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return yamlnetlink.ParseOverlay(f)
+}
+
+// stringsFlag is a flag.Value which may be specified multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
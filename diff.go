@@ -0,0 +1,358 @@
+package yamlnetlink
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// A ChangeKind is the kind of a Change.
+type ChangeKind string
+
+// Possible ChangeKind values.
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// A Change is a single difference between two versions of a Spec, as reported
+// by Diff.
+type Change struct {
+	// Path is the location of the change. Because list indices differ
+	// between versions, items are identified by name, as in
+	// "attribute-sets[channels].attributes[rx-max].value".
+	Path string `json:"path"`
+
+	Kind ChangeKind `json:"kind"`
+
+	// Breaking reports whether the change may break existing users of the
+	// family, such as by renumbering or removing attributes.
+	Breaking bool `json:"breaking"`
+
+	// Old and New are the values before and after a Changed change.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// String returns a single line description of a Change.
+func (c Change) String() string {
+	status := "compatible"
+	if c.Breaking {
+		status = "BREAKING"
+	}
+
+	s := fmt.Sprintf("%s: %s %s", status, c.Kind, c.Path)
+	if c.Kind == Changed {
+		s += fmt.Sprintf(": %q -> %q", c.Old, c.New)
+	}
+
+	return s
+}
+
+// Changes is a list of Changes returned by Diff.
+type Changes []Change
+
+// Breaking reports whether any of the Changes are breaking.
+func (cs Changes) Breaking() bool {
+	for _, c := range cs {
+		if c.Breaking {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Diff compares two versions of a Spec and reports the differences which
+// affect users of the family, classifying each as compatible or breaking.
+// Documentation changes are not reported.
+//
+// Removing definitions, attributes, operations, or multicast groups is
+// breaking, as is changing the numeric value, type, encoding, or references of
+// an existing item. Adding items is compatible, with the exception of struct
+// members which are not appended to the end of a struct. Lengths and bounds
+// are compared by their values where both versions can be evaluated by Eval.
+func Diff(old, new *Spec) Changes {
+	d := &differ{old: old, new: new}
+
+	d.field("name", old.Name, new.Name, true)
+	d.field("protocol", protocol(old), protocol(new), true)
+	d.field("protonum", old.Protonum, new.Protonum, true)
+
+	diffNamed(d, "definitions", old.Definitions, new.Definitions,
+		func(df Definition) string { return df.Name }, d.definition)
+	diffNamed(d, "attribute-sets", old.AttributeSets, new.AttributeSets,
+		func(as AttributeSet) string { return as.Name }, d.attributeSet)
+
+	d.field("operations.name-prefix", old.Operations.NamePrefix, new.Operations.NamePrefix, true)
+	d.field("operations.fixed-header", old.Operations.FixedHeader, new.Operations.FixedHeader, true)
+	diffNamed(d, "operations.list", old.Operations.List, new.Operations.List,
+		func(op Operation) string { return op.Name }, d.operation)
+
+	diffNamed(d, "mcast-groups.list", old.MulticastGroups.List, new.MulticastGroups.List,
+		func(g MulticastGroup) string { return g.Name },
+		func(path string, o, n MulticastGroup) {
+			d.field(path+".value", o.Value, n.Value, true)
+		})
+
+	diffNamed(d, "sub-messages", old.SubMessages, new.SubMessages,
+		func(sm SubMessage) string { return sm.Name },
+		func(path string, o, n SubMessage) {
+			diffNamed(d, path+".formats", o.Formats, n.Formats,
+				func(f SubMessageFormat) string { return f.Value },
+				func(path string, o, n SubMessageFormat) {
+					d.field(path+".fixed-header", o.FixedHeader, n.FixedHeader, true)
+					d.field(path+".attribute-set", o.AttributeSet, n.AttributeSet, true)
+				})
+		})
+
+	return d.cs
+}
+
+// A differ accumulates Changes between two versions of a Spec.
+type differ struct {
+	old, new *Spec
+	cs       Changes
+}
+
+// add records a Change.
+func (d *differ) add(path string, kind ChangeKind, breaking bool) {
+	d.cs = append(d.cs, Change{Path: path, Kind: kind, Breaking: breaking})
+}
+
+// field records a Changed change at path if old and new differ.
+func (d *differ) field(path string, old, new any, breaking bool) {
+	o, n := fmt.Sprint(old), fmt.Sprint(new)
+	if o == n {
+		return
+	}
+
+	d.cs = append(d.cs, Change{
+		Path:     path,
+		Kind:     Changed,
+		Breaking: breaking,
+		Old:      o,
+		New:      n,
+	})
+}
+
+// expr records a Changed change at path if the old and new expressions differ.
+// Expressions which evaluate to the same value in their respective Specs, such
+// as "GENL_NAMSIZ - 1" and "15", are equal, and the same expression is changed
+// if the constants it refers to change its value. If either cannot be
+// evaluated, the expressions are compared as strings.
+func (d *differ) expr(path, old, new string, breaking bool) {
+	if old != "" && new != "" {
+		o, oerr := d.old.eval(old)
+		n, nerr := d.new.eval(new)
+		if oerr == nil && nerr == nil {
+			if o.Cmp(n) != 0 {
+				// Include the values, since the expressions may be equal.
+				d.field(path, fmt.Sprintf("%s (%s)", old, o), fmt.Sprintf("%s (%s)", new, n), breaking)
+			}
+
+			return
+		}
+	}
+
+	d.field(path, old, new, breaking)
+}
+
+// definition compares two versions of a Definition.
+func (d *differ) definition(path string, o, n Definition) {
+	if o.Type != n.Type {
+		// Nothing else is comparable.
+		d.field(path+".type", o.Type, n.Type, true)
+		return
+	}
+
+	d.field(path+".value", o.Value, n.Value, true)
+	d.field(path+".name-prefix", o.NamePrefix, n.NamePrefix, true)
+
+	diffNamed(d, path+".entries", o.Entries, n.Entries,
+		func(e EnumEntry) string { return e.Name },
+		func(path string, o, n EnumEntry) {
+			d.field(path+".value", o.Value, n.Value, true)
+		})
+
+	// Struct members are positional, so only appending members is
+	// compatible.
+	var (
+		common   = len(o.Members)
+		breaking bool
+	)
+
+	if len(n.Members) < common {
+		common, breaking = len(n.Members), true
+	}
+
+	for i := 0; i < common; i++ {
+		om, nm := o.Members[i], n.Members[i]
+		if om.Name != nm.Name {
+			d.field(fmt.Sprintf("%s.members[%d].name", path, i), om.Name, nm.Name, true)
+			continue
+		}
+
+		mpath := fmt.Sprintf("%s.members[%s]", path, om.Name)
+		d.field(mpath+".type", om.Type, nm.Type, true)
+		d.expr(mpath+".len", om.Len, nm.Len, true)
+		d.field(mpath+".byte-order", om.ByteOrder, nm.ByteOrder, true)
+		d.field(mpath+".enum", om.Enum, nm.Enum, true)
+	}
+
+	for _, m := range o.Members[common:] {
+		d.add(fmt.Sprintf("%s.members[%s]", path, m.Name), Removed, breaking)
+	}
+	for _, m := range n.Members[common:] {
+		d.add(fmt.Sprintf("%s.members[%s]", path, m.Name), Added, breaking)
+	}
+}
+
+// attributeSet compares two versions of an AttributeSet.
+func (d *differ) attributeSet(path string, o, n AttributeSet) {
+	d.field(path+".name-prefix", o.NamePrefix, n.NamePrefix, true)
+	d.field(path+".subset-of", o.SubsetOf, n.SubsetOf, true)
+
+	diffNamed(d, path+".attributes", o.Attributes, n.Attributes,
+		func(a Attribute) string { return a.Name },
+		func(path string, o, n Attribute) {
+			d.field(path+".value", o.Value, n.Value, true)
+			d.field(path+".type", o.Type, n.Type, true)
+			d.field(path+".sub-type", o.SubType, n.SubType, true)
+			d.expr(path+".len", o.Len, n.Len, true)
+			d.field(path+".nested-attributes", o.NestedAttributes, n.NestedAttributes, true)
+			d.field(path+".byte-order", o.ByteOrder, n.ByteOrder, true)
+			d.field(path+".multi-attr", o.MultiAttr, n.MultiAttr, true)
+			d.field(path+".enum", o.Enum, n.Enum, true)
+			d.field(path+".enum-as-flags", o.EnumAsFlags, n.EnumAsFlags, true)
+			d.field(path+".struct", o.Struct, n.Struct, true)
+			d.field(path+".sub-message", o.SubMessage, n.SubMessage, true)
+			d.field(path+".selector", o.Selector, n.Selector, true)
+
+			// Presentation and validation changes do not alter the wire
+			// format.
+			d.field(path+".display-hint", o.DisplayHint, n.DisplayHint, false)
+			d.expr(path+".checks.min", o.Checks.Min, n.Checks.Min, false)
+			d.expr(path+".checks.max", o.Checks.Max, n.Checks.Max, false)
+			d.expr(path+".checks.min-len", o.Checks.MinLen, n.Checks.MinLen, false)
+			d.expr(path+".checks.max-len", o.Checks.MaxLen, n.Checks.MaxLen, false)
+			d.expr(path+".checks.exact-len", o.Checks.ExactLen, n.Checks.ExactLen, false)
+			d.field(path+".checks.unterminated-ok", o.Checks.UnterminatedOK, n.Checks.UnterminatedOK, false)
+		})
+}
+
+// operation compares two versions of an Operation.
+func (d *differ) operation(path string, o, n Operation) {
+	d.field(path+".value", o.Value, n.Value, true)
+	d.field(path+".reply-value", o.ReplyValue, n.ReplyValue, true)
+	d.field(path+".attribute-set", o.AttributeSet, n.AttributeSet, true)
+	d.field(path+".notify", o.Notify, n.Notify, true)
+	d.field(path+".mcgrp", o.MulticastGroup, n.MulticastGroup, true)
+	d.field(path+".fixed-header", o.FixedHeader, n.FixedHeader, true)
+
+	// Requiring privileges is breaking for unprivileged users.
+	d.field(path+".flags", flags(o.Flags), flags(n.Flags), !o.Privileged() && n.Privileged())
+
+	d.mode(path+".do", o.Do, n.Do)
+	d.mode(path+".dump", o.Dump, n.Dump)
+	d.list(path+".event", o.Event, n.Event)
+}
+
+// mode compares two versions of the OperationAttributes of a do or dump.
+func (d *differ) mode(path string, o, n OperationAttributes) {
+	switch oh, nh := hasMode(o), hasMode(n); {
+	case oh && !nh:
+		d.add(path, Removed, true)
+		return
+	case !oh && nh:
+		d.add(path, Added, false)
+		return
+	case !oh && !nh:
+		return
+	}
+
+	d.list(path+".request", o.Request, n.Request)
+	d.list(path+".reply", o.Reply, n.Reply)
+}
+
+// list compares two versions of an OperationAttributesList. Removing an
+// attribute is breaking, but adding one is compatible.
+func (d *differ) list(path string, o, n OperationAttributesList) {
+	d.field(path+".value", o.Value, n.Value, true)
+
+	for _, a := range o.Attributes {
+		if !slices.Contains(n.Attributes, a) {
+			d.add(fmt.Sprintf("%s.attributes[%s]", path, a), Removed, true)
+		}
+	}
+	for _, a := range n.Attributes {
+		if !slices.Contains(o.Attributes, a) {
+			d.add(fmt.Sprintf("%s.attributes[%s]", path, a), Added, false)
+		}
+	}
+}
+
+// diffNamed compares two lists of items matched by name. Removing an item is
+// breaking and adding one is compatible. each compares items which exist in
+// both lists.
+func diffNamed[T any](
+	d *differ,
+	path string,
+	old, new []T,
+	name func(T) string,
+	each func(path string, o, n T),
+) {
+	index := func(ts []T) map[string]T {
+		m := make(map[string]T, len(ts))
+		for _, t := range ts {
+			m[name(t)] = t
+		}
+
+		return m
+	}
+
+	oi, ni := index(old), index(new)
+	for _, o := range old {
+		ipath := fmt.Sprintf("%s[%s]", path, name(o))
+		if n, ok := ni[name(o)]; ok {
+			each(ipath, o, n)
+		} else {
+			d.add(ipath, Removed, true)
+		}
+	}
+
+	for _, n := range new {
+		if _, ok := oi[name(n)]; !ok {
+			d.add(fmt.Sprintf("%s[%s]", path, name(n)), Added, false)
+		}
+	}
+}
+
+// protocol returns the protocol of s, applying the default.
+func protocol(s *Spec) string {
+	if s.Protocol == "" {
+		return "genetlink"
+	}
+
+	return s.Protocol
+}
+
+// flags returns a string representation of OperationFlags.
+func flags(fs []OperationFlag) string {
+	ss := make([]string, 0, len(fs))
+	for _, f := range fs {
+		ss = append(ss, string(f))
+	}
+
+	return strings.Join(ss, ",")
+}
+
+// hasMode reports whether OperationAttributes describe a do or dump which is
+// present in an Operation.
+func hasMode(oas OperationAttributes) bool {
+	return oas.Pre != "" || oas.Post != "" ||
+		oas.Request.Value != 0 || len(oas.Request.Attributes) > 0 ||
+		oas.Reply.Value != 0 || len(oas.Reply.Attributes) > 0
+}
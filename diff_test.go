@@ -0,0 +1,240 @@
+package yamlnetlink_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/yamlnetlink"
+)
+
+func TestDiffNoChanges(t *testing.T) {
+	for _, family := range []string{"ethtool", "nlctrl"} {
+		t.Run(family, func(t *testing.T) {
+			var (
+				old = parseFile(t, "testdata/"+family+"/"+family+".yaml")
+				new = parseFile(t, "testdata/"+family+"/"+family+".yaml")
+			)
+
+			if diff := cmp.Diff(yamlnetlink.Changes(nil), yamlnetlink.Diff(old, new)); diff != "" {
+				t.Fatalf("unexpected Changes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	const (
+		old = `
+name: test
+definitions:
+  - { name: mode, type: enum, entries: [ a, b, c ] }
+  - { name: gone, type: const, value: 1 }
+  -
+    name: hdr
+    type: struct
+    members:
+      - { name: x, type: u32 }
+      - { name: y, type: u16 }
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: foo, type: u32 }
+      - { name: bar, type: u32, display-hint: hex }
+      - { name: baz, type: nest, nested-attributes: nest }
+      - { name: qux, type: u32 }
+  -
+    name: nest
+    attributes:
+      - { name: a, type: u8 }
+  -
+    name: other
+    attributes:
+      - { name: a, type: u8 }
+operations:
+  list:
+    -
+      name: get
+      doc: Get.
+      attribute-set: main
+      do:
+        request:
+          attributes: [ foo, bar ]
+        reply:
+          attributes: [ foo, bar, baz ]
+    -
+      name: set
+      attribute-set: main
+      do:
+        request:
+          attributes: [ foo ]
+    -
+      name: del
+      attribute-set: main
+`
+
+		new = `
+name: test
+definitions:
+  - { name: mode, type: enum, entries: [ b, a, c, d ] }
+  -
+    name: hdr
+    type: struct
+    members:
+      - { name: x, type: u32 }
+      - { name: y, type: u16 }
+      - { name: z, type: u16 }
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: foo, type: u64 }
+      - { name: bar, type: u32, display-hint: mac }
+      - { name: baz, type: nest, nested-attributes: other }
+      - { name: new, type: u32 }
+  -
+    name: nest
+    attributes:
+      - { name: a, type: u8 }
+  -
+    name: other
+    attributes:
+      - { name: a, type: u8 }
+operations:
+  list:
+    -
+      name: get
+      doc: Get something else.
+      attribute-set: main
+      do:
+        request:
+          attributes: [ foo, bar, new ]
+        reply:
+          attributes: [ foo, baz ]
+    -
+      name: set
+      attribute-set: main
+      flags: [ admin-perm ]
+      dump:
+        request:
+          attributes: [ foo ]
+    -
+      name: new
+      attribute-set: main
+`
+	)
+
+	var specs []*yamlnetlink.Spec
+	for _, in := range []string{old, new} {
+		s, err := yamlnetlink.Parse(strings.NewReader(in))
+		if err != nil {
+			t.Fatalf("failed to parse YAML: %v", err)
+		}
+
+		specs = append(specs, s)
+	}
+
+	got := yamlnetlink.Diff(specs[0], specs[1])
+
+	want := yamlnetlink.Changes{
+		{Path: "definitions[mode].entries[a].value", Kind: yamlnetlink.Changed, Breaking: true, Old: "0", New: "1"},
+		{Path: "definitions[mode].entries[b].value", Kind: yamlnetlink.Changed, Breaking: true, Old: "1", New: "0"},
+		{Path: "definitions[mode].entries[d]", Kind: yamlnetlink.Added},
+		{Path: "definitions[gone]", Kind: yamlnetlink.Removed, Breaking: true},
+		{Path: "definitions[hdr].members[z]", Kind: yamlnetlink.Added},
+		{Path: "attribute-sets[main].attributes[foo].type", Kind: yamlnetlink.Changed, Breaking: true, Old: "u32", New: "u64"},
+		{Path: "attribute-sets[main].attributes[bar].display-hint", Kind: yamlnetlink.Changed, Old: "hex", New: "mac"},
+		{Path: "attribute-sets[main].attributes[baz].nested-attributes", Kind: yamlnetlink.Changed, Breaking: true, Old: "nest", New: "other"},
+		{Path: "attribute-sets[main].attributes[qux]", Kind: yamlnetlink.Removed, Breaking: true},
+		{Path: "attribute-sets[main].attributes[new]", Kind: yamlnetlink.Added},
+		{Path: "operations.list[get].do.request.attributes[new]", Kind: yamlnetlink.Added},
+		{Path: "operations.list[get].do.reply.attributes[bar]", Kind: yamlnetlink.Removed, Breaking: true},
+		{Path: "operations.list[set].flags", Kind: yamlnetlink.Changed, Breaking: true, New: "admin-perm"},
+		{Path: "operations.list[set].do", Kind: yamlnetlink.Removed, Breaking: true},
+		{Path: "operations.list[set].dump", Kind: yamlnetlink.Added},
+		{Path: "operations.list[del]", Kind: yamlnetlink.Removed, Breaking: true},
+		{Path: "operations.list[new]", Kind: yamlnetlink.Added},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected Changes (-want +got):\n%s", diff)
+	}
+
+	if !got.Breaking() {
+		t.Fatal("expected breaking changes")
+	}
+}
+
+func TestDiffStructMembers(t *testing.T) {
+	parse := func(members string) *yamlnetlink.Spec {
+		s, err := yamlnetlink.Parse(strings.NewReader(
+			"name: test\ndefinitions:\n  - { name: hdr, type: struct, members: " + members + " }\n"))
+		if err != nil {
+			t.Fatalf("failed to parse YAML: %v", err)
+		}
+
+		return s
+	}
+
+	got := yamlnetlink.Diff(
+		parse("[ { name: x, type: u32 }, { name: y, type: u16 } ]"),
+		parse("[ { name: y, type: u16 } ]"),
+	)
+
+	want := yamlnetlink.Changes{
+		{Path: "definitions[hdr].members[0].name", Kind: yamlnetlink.Changed, Breaking: true, Old: "x", New: "y"},
+		{Path: "definitions[hdr].members[y]", Kind: yamlnetlink.Removed, Breaking: true},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected Changes (-want +got):\n%s", diff)
+	}
+
+	const str = `BREAKING: changed definitions[hdr].members[0].name: "x" -> "y"`
+	if diff := cmp.Diff(str, got[0].String()); diff != "" {
+		t.Fatalf("unexpected string (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiffExpressions(t *testing.T) {
+	parse := func(consts, attrs string) *yamlnetlink.Spec {
+		s, err := yamlnetlink.Parse(strings.NewReader(
+			"name: test\ndefinitions: " + consts + "\nattribute-sets:\n  - { name: main, attributes: " + attrs + " }\n"))
+		if err != nil {
+			t.Fatalf("failed to parse YAML: %v", err)
+		}
+
+		return s
+	}
+
+	got := yamlnetlink.Diff(
+		parse(
+			"[ { name: max-name, type: const, value: 16 } ]",
+			`[ { name: a, type: string, len: GENL_NAMSIZ - 1 },
+			   { name: b, type: string, len: max-name },
+			   { name: c, type: string, len: NAMSIZ },
+			   { name: d, type: u8, checks: { max: u8-max } } ]`,
+		),
+		parse(
+			"[ { name: max-name, type: const, value: 32 } ]",
+			`[ { name: a, type: string, len: 15 },
+			   { name: b, type: string, len: max-name },
+			   { name: c, type: string, len: NAMSIZ - 1 },
+			   { name: d, type: u8, checks: { max: 255 } } ]`,
+		),
+	)
+
+	// Equal values are not changes, but a change to a constant changes the
+	// value of expressions which use it. Unresolved names fall back to
+	// comparing strings.
+	want := yamlnetlink.Changes{
+		{Path: "definitions[max-name].value", Kind: yamlnetlink.Changed, Breaking: true, Old: "16", New: "32"},
+		{Path: "attribute-sets[main].attributes[b].len", Kind: yamlnetlink.Changed, Breaking: true, Old: "max-name (16)", New: "max-name (32)"},
+		{Path: "attribute-sets[main].attributes[c].len", Kind: yamlnetlink.Changed, Breaking: true, Old: "NAMSIZ", New: "NAMSIZ - 1"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected Changes (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,249 @@
+package yamlnetlink
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A Doc is documentation from a YAML netlink specification. The value of a Doc
+// is the raw text as written in the specification. Blocks and Normalized
+// provide a structured form which preserves paragraphs, lists, and
+// preformatted text such as tables of enum values.
+type Doc string
+
+// A DocBlockKind is the kind of a DocBlock.
+type DocBlockKind string
+
+// Possible DocBlockKind values.
+const (
+	// DocParagraph is a paragraph of text. Its lines are joined with spaces.
+	DocParagraph DocBlockKind = "paragraph"
+
+	// DocList is a bulleted or numbered list. Each list item may span
+	// multiple lines, which are joined with spaces.
+	DocList DocBlockKind = "list"
+
+	// DocPreformatted is text whose line breaks and alignment are
+	// significant, such as an indented block or a table of values.
+	DocPreformatted DocBlockKind = "preformatted"
+)
+
+// A DocBlock is a single block of a Doc.
+type DocBlock struct {
+	Kind DocBlockKind
+
+	// Text contains the normalized text of the block. A DocParagraph has
+	// a single element, a DocList has one element per item without its
+	// marker, and a DocPreformatted block has one element per line with
+	// common indentation removed.
+	Text []string
+}
+
+var (
+	// docListItem matches the marker of a bulleted or numbered list item.
+	docListItem = regexp.MustCompile(`^([-*+]|[0-9]+[.)])\s+`)
+
+	// docTableRow matches a row of a table such as "0 - disabled".
+	docTableRow = regexp.MustCompile(`^[\w.()]+\s+[-=]\s+\S`)
+)
+
+// Blocks parses the Doc into blocks of paragraphs, lists, and preformatted
+// text. Blocks are separated by blank lines or changes in their kind.
+func (d Doc) Blocks() []DocBlock {
+	var (
+		blocks []DocBlock
+		chunk  []string
+	)
+
+	for _, l := range strings.Split(string(d), "\n") {
+		l = strings.TrimRight(l, " \t")
+		if l != "" {
+			chunk = append(chunk, l)
+			continue
+		}
+
+		blocks = append(blocks, docChunk(chunk)...)
+		chunk = nil
+	}
+
+	return append(blocks, docChunk(chunk)...)
+}
+
+// docChunk parses a chunk of non-blank lines into blocks.
+func docChunk(lines []string) []DocBlock {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	// Lines which are indented relative to the chunk, contain aligned
+	// columns, or form a table are preformatted.
+	base := indentOf(lines[0])
+	for _, l := range lines[1:] {
+		if i := indentOf(l); i < base {
+			base = i
+		}
+	}
+
+	var tableRows int
+	for _, l := range lines {
+		if docTableRow.MatchString(strings.TrimSpace(l)) {
+			tableRows++
+		}
+	}
+	table := tableRows > 1 && tableRows == len(lines)
+
+	var (
+		blocks []DocBlock
+		cur    *DocBlock
+
+		// The indentation of the current list item's text.
+		itemIndent int
+	)
+
+	add := func(kind DocBlockKind) {
+		blocks = append(blocks, DocBlock{Kind: kind})
+		cur = &blocks[len(blocks)-1]
+	}
+
+	for _, l := range lines {
+		var (
+			indent = indentOf(l) - base
+			text   = strings.TrimSpace(l)
+		)
+
+		if m := docListItem.FindString(text); m != "" && !table {
+			if cur == nil || cur.Kind != DocList {
+				add(DocList)
+			}
+
+			cur.Text = append(cur.Text, strings.TrimPrefix(text, m))
+			itemIndent = indent + len(m)
+			continue
+		}
+
+		if cur != nil && cur.Kind == DocList && indent > 0 && indent <= itemIndent {
+			// Continuation of a list item.
+			cur.Text[len(cur.Text)-1] += " " + text
+			continue
+		}
+
+		if table || indent > 0 || strings.Contains(text, "\t") || strings.Contains(text, "   ") {
+			if cur == nil || cur.Kind != DocPreformatted {
+				add(DocPreformatted)
+			}
+
+			cur.Text = append(cur.Text, l)
+			continue
+		}
+
+		if cur == nil || cur.Kind != DocParagraph {
+			add(DocParagraph)
+			cur.Text = []string{text}
+			continue
+		}
+
+		cur.Text[0] += " " + text
+	}
+
+	// Remove the common indentation of preformatted blocks.
+	for _, b := range blocks {
+		if b.Kind != DocPreformatted {
+			continue
+		}
+
+		min := -1
+		for _, l := range b.Text {
+			if i := indentOf(l); min == -1 || i < min {
+				min = i
+			}
+		}
+		for i := range b.Text {
+			b.Text[i] = b.Text[i][min:]
+		}
+	}
+
+	return blocks
+}
+
+// indentOf returns the number of leading spaces and tabs of s.
+func indentOf(s string) int { return len(s) - len(strings.TrimLeft(s, " \t")) }
+
+// Normalized returns the Doc with its whitespace normalized: blocks are
+// separated by a blank line, paragraphs are joined onto a single line, list
+// items each occupy a single line beginning with "- ", and preformatted lines
+// are indented by two spaces.
+func (d Doc) Normalized() string {
+	var ss []string
+	for _, b := range d.Blocks() {
+		var lines []string
+		switch b.Kind {
+		case DocParagraph:
+			lines = b.Text
+		case DocList:
+			for _, t := range b.Text {
+				lines = append(lines, "- "+t)
+			}
+		case DocPreformatted:
+			for _, t := range b.Text {
+				lines = append(lines, "  "+t)
+			}
+		}
+
+		ss = append(ss, strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(ss, "\n\n")
+}
+
+// comment formats the Doc as a Go comment whose lines are wrapped at width
+// columns, following the conventions of Go doc comments for lists and code
+// blocks. Each line begins with "//".
+func (d Doc) comment(width int) []string {
+	var lines []string
+	for i, b := range d.Blocks() {
+		if i > 0 {
+			lines = append(lines, "//")
+		}
+
+		switch b.Kind {
+		case DocParagraph:
+			lines = append(lines, wrap(b.Text[0], "// ", "// ", width)...)
+		case DocList:
+			for _, t := range b.Text {
+				lines = append(lines, wrap(t, "//   - ", "//     ", width)...)
+			}
+		case DocPreformatted:
+			for _, t := range b.Text {
+				lines = append(lines, "//\t"+t)
+			}
+		}
+	}
+
+	return lines
+}
+
+// wrap wraps the words of s into lines no longer than width, where possible.
+// The first line begins with first and the following lines with rest.
+func wrap(s, first, rest string, width int) []string {
+	var (
+		lines []string
+		line  = first
+		empty = true
+	)
+
+	for _, w := range strings.Fields(s) {
+		if !empty && len(line)+1+len(w) > width {
+			lines = append(lines, line)
+			line, empty = rest, true
+		}
+
+		if !empty {
+			line += " "
+		}
+
+		line += w
+		empty = false
+	}
+
+	return append(lines, line)
+}
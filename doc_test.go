@@ -0,0 +1,117 @@
+package yamlnetlink_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/yamlnetlink"
+)
+
+func TestDocBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  yamlnetlink.Doc
+		want []yamlnetlink.DocBlock
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "paragraph",
+			doc:  "Generic netlink control protocol. Interface to query\ninformation about families.\n",
+			want: []yamlnetlink.DocBlock{{
+				Kind: yamlnetlink.DocParagraph,
+				Text: []string{"Generic netlink control protocol. Interface to query information about families."},
+			}},
+		},
+		{
+			name: "paragraphs",
+			doc:  "First paragraph\nof text.\n\nSecond paragraph.\n",
+			want: []yamlnetlink.DocBlock{
+				{Kind: yamlnetlink.DocParagraph, Text: []string{"First paragraph of text."}},
+				{Kind: yamlnetlink.DocParagraph, Text: []string{"Second paragraph."}},
+			},
+		},
+		{
+			name: "list",
+			doc: `Supported modes:
+- off: no offload
+- on: offload enabled
+  on all queues
+1. numbered
+`,
+			want: []yamlnetlink.DocBlock{
+				{Kind: yamlnetlink.DocParagraph, Text: []string{"Supported modes:"}},
+				{
+					Kind: yamlnetlink.DocList,
+					Text: []string{
+						"off: no offload",
+						"on: offload enabled on all queues",
+						"numbered",
+					},
+				},
+			},
+		},
+		{
+			name: "table",
+			doc: `Link mode.
+
+0 - disabled
+1 - enabled
+`,
+			want: []yamlnetlink.DocBlock{
+				{Kind: yamlnetlink.DocParagraph, Text: []string{"Link mode."}},
+				{Kind: yamlnetlink.DocPreformatted, Text: []string{"0 - disabled", "1 - enabled"}},
+			},
+		},
+		{
+			name: "preformatted",
+			doc: `Example:
+    struct foo {
+        int bar;
+    };
+After.`,
+			want: []yamlnetlink.DocBlock{
+				{Kind: yamlnetlink.DocParagraph, Text: []string{"Example:"}},
+				{
+					Kind: yamlnetlink.DocPreformatted,
+					Text: []string{"struct foo {", "    int bar;", "};"},
+				},
+				{Kind: yamlnetlink.DocParagraph, Text: []string{"After."}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, tt.doc.Blocks()); diff != "" {
+				t.Fatalf("unexpected blocks (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDocNormalized(t *testing.T) {
+	const doc = `Interface flags,
+as a bitmask.
+
+- up
+- running:
+  carrier present
+
+0 - none
+1 - some
+`
+
+	const want = `Interface flags, as a bitmask.
+
+- up
+- running: carrier present
+
+  0 - none
+  1 - some`
+
+	if diff := cmp.Diff(want, yamlnetlink.Doc(doc).Normalized()); diff != "" {
+		t.Fatalf("unexpected normalized doc (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,466 @@
+package yamlnetlink
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// wellKnown contains constants from the kernel's UAPI headers which are
+// commonly referenced by specifications without a matching definition.
+var wellKnown = map[string]int64{
+	"ALTIFNAMSIZ":     128,
+	"ETH_ALEN":        6,
+	"ETH_GSTRING_LEN": 32,
+	"GENL_NAMSIZ":     16,
+	"IFALIASZ":        256,
+	"IFNAMSIZ":        16,
+}
+
+// limit matches the name of the minimum or maximum value of a fixed-width
+// integer type, such as "u16-max".
+var limit = regexp.MustCompile(`^([su])(8|16|32|64)-(min|max)$`)
+
+// An ExprError is returned by Spec.Eval and Spec.EvalUint when an expression
+// cannot be evaluated.
+type ExprError struct {
+	// Expr is the expression.
+	Expr string
+
+	// Unresolved contains each name in Expr which does not refer to a
+	// constant. If Unresolved is empty, Message describes the problem.
+	Unresolved []string
+
+	// Message describes a syntax or arithmetic error.
+	Message string
+}
+
+// Error implements error.
+func (e *ExprError) Error() string {
+	return fmt.Sprintf("yamlnetlink: expression %q: %s", e.Expr, e.message())
+}
+
+// message describes the problem without the expression.
+func (e *ExprError) message() string {
+	if len(e.Unresolved) == 0 {
+		return e.Message
+	}
+
+	qs := make([]string, 0, len(e.Unresolved))
+	for _, n := range e.Unresolved {
+		qs = append(qs, strconv.Quote(n))
+	}
+
+	return "unresolved name " + strings.Join(qs, ", ")
+}
+
+// Eval evaluates a constant expression from the Spec, such as an Attribute's
+// Len or the bounds in its Checks, and returns its integer value.
+//
+// Expressions consist of integers, names, parentheses, and the Go integer
+// operators + - * / % << >> & | ^ and unary - and ^. A name refers to one of,
+// in order of precedence:
+//   - the Value of a const Definition in the Spec, such as "ALTIFNAMSIZ"
+//   - the limit of a fixed-width integer type, such as "u8-max" or "s32-min"
+//   - a well-known kernel constant, such as "GENL_NAMSIZ"
+//
+// Since kebab-case names contain hyphens, a lowercase name must be subtracted
+// from another lowercase name with spaces, as in "max-len - min-len".
+//
+// Expressions are evaluated without wrapping. Every intermediate result must
+// fit in an int64 or a uint64, and the final result must fit in an int64. Use
+// EvalUint for expressions such as "u64-max" which exceed math.MaxInt64.
+//
+// If any names cannot be resolved, Eval returns an *ExprError which reports
+// each of them.
+func (s *Spec) Eval(expr string) (int64, error) {
+	v, err := s.eval(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	if !v.IsInt64() {
+		return 0, &ExprError{Expr: expr, Message: fmt.Sprintf("value %s overflows int64", v)}
+	}
+
+	return v.Int64(), nil
+}
+
+// EvalUint is like Eval, but evaluates an expression whose result must fit in
+// a uint64, such as the maximum bound of a u64 attribute.
+func (s *Spec) EvalUint(expr string) (uint64, error) {
+	v, err := s.eval(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	if !v.IsUint64() {
+		return 0, &ExprError{Expr: expr, Message: fmt.Sprintf("value %s overflows uint64", v)}
+	}
+
+	return v.Uint64(), nil
+}
+
+// eval evaluates an expression whose result fits in an int64 or a uint64.
+func (s *Spec) eval(expr string) (*big.Int, error) {
+	p := &exprParser{s: s, expr: expr}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+
+	v := p.binary(0)
+	if p.err == nil && p.i < len(p.toks) {
+		p.errorf("unexpected %q", p.toks[p.i])
+	}
+
+	// Syntax and arithmetic errors take precedence over unresolved names, since
+	// they cannot be fixed by defining a constant.
+	switch {
+	case p.err != nil:
+		return nil, p.err
+	case len(p.unresolved) > 0:
+		return nil, &ExprError{Expr: expr, Unresolved: p.unresolved}
+	default:
+		return v, nil
+	}
+}
+
+var (
+	// minInt and maxUint are the bounds of every result in an expression.
+	minInt  = big.NewInt(math.MinInt64)
+	maxUint = new(big.Int).SetUint64(math.MaxUint64)
+)
+
+// evalValues returns a copy of the specification mapping n in which each value
+// of a definition, enum entry, attribute, operation, or multicast group which
+// is a constant expression, such as "FOO + 1", is replaced by its result.
+//
+// Expressions may refer to const definitions, but the value of a const
+// definition may only refer to those which precede it.
+func evalValues(n *yaml.Node) (*yaml.Node, error) {
+	n = copyNode(n)
+
+	consts := new(Spec)
+	for _, dn := range items(valueOf(n, "definitions")) {
+		if err := consts.evalValue(dn); err != nil {
+			return nil, err
+		}
+
+		if t := valueOf(dn, "type"); t == nil || t.Value != string(DefinitionConst) {
+			continue
+		}
+
+		var d Definition
+		if err := dn.Decode(&d); err != nil {
+			return nil, err
+		}
+		consts.Definitions = append(consts.Definitions, d)
+	}
+
+	var ns []*yaml.Node
+	for _, dn := range items(valueOf(n, "definitions")) {
+		ns = append(ns, items(valueOf(dn, "entries"))...)
+	}
+	for _, as := range items(valueOf(n, "attribute-sets")) {
+		ns = append(ns, items(valueOf(as, "attributes"))...)
+	}
+	for _, op := range items(valueOf(valueOf(n, "operations"), "list")) {
+		ns = append(ns, op)
+		for _, mode := range []string{"do", "dump"} {
+			for _, dir := range []string{"request", "reply"} {
+				ns = append(ns, valueOf(valueOf(op, mode), dir))
+			}
+		}
+	}
+	ns = append(ns, items(valueOf(valueOf(n, "mcast-groups"), "list"))...)
+
+	for _, vn := range ns {
+		if err := consts.evalValue(vn); err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
+
+// evalValue replaces the value of mapping n with its result if it is a
+// constant expression rather than an integer.
+func (s *Spec) evalValue(n *yaml.Node) error {
+	vn := valueOf(n, "value")
+	if vn == nil || vn.Kind != yaml.ScalarNode {
+		return nil
+	}
+	if _, err := strconv.ParseInt(vn.Value, 0, 64); err == nil {
+		return nil
+	}
+
+	v, err := s.Eval(vn.Value)
+	if err != nil {
+		// Eval always returns *ExprError.
+		return fmt.Errorf("yamlnetlink: line %d: invalid value %q: %s",
+			vn.Line, vn.Value, err.(*ExprError).message())
+	}
+
+	vn.Tag, vn.Style, vn.Value = "!!int", 0, strconv.FormatInt(v, 10)
+	return nil
+}
+
+// items returns the items of sequence node n, or nil if n is not a sequence.
+func items(n *yaml.Node) []*yaml.Node {
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	return n.Content
+}
+
+// lookup returns the value of a name in an expression.
+func (s *Spec) lookup(name string) (*big.Int, bool) {
+	for _, d := range s.Definitions {
+		if d.Type == DefinitionConst && d.Name == name {
+			return big.NewInt(int64(d.Value)), true
+		}
+	}
+
+	if m := limit.FindStringSubmatch(name); m != nil {
+		bits, _ := strconv.Atoi(m[2])
+		v := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+		switch {
+		case m[1] == "u" && m[3] == "min":
+			return v.SetInt64(0), true
+		case m[1] == "u":
+			return v.Sub(v, big.NewInt(1)), true
+		case m[3] == "min":
+			return v.Rsh(v, 1).Neg(v), true
+		default:
+			return v.Rsh(v, 1).Sub(v, big.NewInt(1)), true
+		}
+	}
+
+	v, ok := wellKnown[name]
+	return big.NewInt(v), ok
+}
+
+// An exprParser is a recursive descent parser which evaluates an expression.
+type exprParser struct {
+	s    *Spec
+	expr string
+	toks []string
+	i    int
+
+	unresolved []string
+	err        error
+}
+
+// binaryPrec is the precedence of each binary operator, as in Go.
+var binaryPrec = map[string]int{
+	"|": 1, "^": 1, "+": 1, "-": 1,
+	"*": 2, "/": 2, "%": 2, "<<": 2, ">>": 2, "&": 2,
+}
+
+// errorf records the first syntax or arithmetic error.
+func (p *exprParser) errorf(format string, v ...any) {
+	if p.err == nil {
+		p.err = &ExprError{Expr: p.expr, Message: fmt.Sprintf(format, v...)}
+	}
+}
+
+// tokenize splits the expression into tokens.
+func (p *exprParser) tokenize() error {
+	s := p.expr
+	for len(s) > 0 {
+		c := s[0]
+		switch {
+		case c == ' ' || c == '\t':
+			s = s[1:]
+			continue
+		case strings.HasPrefix(s, "<<") || strings.HasPrefix(s, ">>"):
+			p.toks = append(p.toks, s[:2])
+			s = s[2:]
+			continue
+		case strings.IndexByte("+-*/%&|^()", c) >= 0:
+			p.toks = append(p.toks, s[:1])
+			s = s[1:]
+			continue
+		case !isWord(c):
+			p.errorf("unexpected %q", c)
+			return p.err
+		}
+
+		// Names and numbers. Hyphens join lowercase words within a kebab-case
+		// name, as in "u8-max", so subtraction between two such names must be
+		// surrounded by spaces.
+		n := 1
+		for n < len(s) && (isWord(s[n]) || s[n] == '-' && n+1 < len(s) && isLower(s[n+1]) && isKebab(s[:n])) {
+			n++
+		}
+
+		p.toks = append(p.toks, s[:n])
+		s = s[n:]
+	}
+
+	if len(p.toks) == 0 {
+		p.errorf("empty expression")
+		return p.err
+	}
+
+	return nil
+}
+
+// isWord reports whether c may appear in a name or number.
+func isWord(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// isLower reports whether c is a lowercase letter.
+func isLower(c byte) bool { return 'a' <= c && c <= 'z' }
+
+// isKebab reports whether s may begin a kebab-case name: a lowercase letter
+// followed by lowercase letters, digits, and hyphens.
+func isKebab(s string) bool {
+	if s == "" || !isLower(s[0]) {
+		return false
+	}
+
+	for i := 1; i < len(s); i++ {
+		if c := s[i]; !isLower(c) && !('0' <= c && c <= '9') && c != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// next returns the next token, or the empty string at the end of the
+// expression.
+func (p *exprParser) next() string {
+	if p.i == len(p.toks) {
+		return ""
+	}
+
+	t := p.toks[p.i]
+	p.i++
+	return t
+}
+
+// peek returns the next token without consuming it.
+func (p *exprParser) peek() string {
+	if p.i == len(p.toks) {
+		return ""
+	}
+
+	return p.toks[p.i]
+}
+
+// binary parses a sequence of binary operations whose operators have a
+// precedence greater than prec.
+func (p *exprParser) binary(prec int) *big.Int {
+	x := p.unary()
+	for {
+		op := p.peek()
+		oprec, ok := binaryPrec[op]
+		if !ok || oprec <= prec {
+			return x
+		}
+
+		p.next()
+		x = p.apply(op, x, p.binary(oprec))
+	}
+}
+
+// apply applies binary operator op.
+func (p *exprParser) apply(op string, x, y *big.Int) *big.Int {
+	z := new(big.Int)
+	switch op {
+	case "+":
+		z.Add(x, y)
+	case "-":
+		z.Sub(x, y)
+	case "*":
+		z.Mul(x, y)
+	case "/", "%":
+		if y.Sign() == 0 {
+			p.errorf("division by zero")
+			return z
+		}
+		// Quo and Rem truncate toward zero, as in Go.
+		if op == "/" {
+			z.Quo(x, y)
+		} else {
+			z.Rem(x, y)
+		}
+	case "<<", ">>":
+		if y.Sign() < 0 || y.Cmp(big.NewInt(63)) > 0 {
+			p.errorf("invalid shift count %s", y)
+			return z
+		}
+		if op == "<<" {
+			z.Lsh(x, uint(y.Uint64()))
+		} else {
+			z.Rsh(x, uint(y.Uint64()))
+		}
+	case "&":
+		z.And(x, y)
+	case "|":
+		z.Or(x, y)
+	default: // "^"
+		z.Xor(x, y)
+	}
+
+	return p.check(z, "%s %s %s", x, op, y)
+}
+
+// check records an error if v, the result of the operation described by
+// format, does not fit in an int64 or a uint64.
+func (p *exprParser) check(v *big.Int, format string, a ...any) *big.Int {
+	if v.Cmp(minInt) < 0 || v.Cmp(maxUint) > 0 {
+		p.errorf("%s overflows 64 bits", fmt.Sprintf(format, a...))
+	}
+
+	return v
+}
+
+// unary parses a unary operation or an operand.
+func (p *exprParser) unary() *big.Int {
+	switch t := p.next(); {
+	case t == "-":
+		x := p.unary()
+		return p.check(new(big.Int).Neg(x), "-%s", x)
+	case t == "+":
+		return p.unary()
+	case t == "^":
+		x := p.unary()
+		return p.check(new(big.Int).Not(x), "^%s", x)
+	case t == "(":
+		v := p.binary(0)
+		if p.next() != ")" {
+			p.errorf("missing )")
+		}
+		return v
+	case t == "":
+		p.errorf("unexpected end of expression")
+		return new(big.Int)
+	case t[0] >= '0' && t[0] <= '9':
+		v, ok := new(big.Int).SetString(t, 0)
+		if !ok {
+			p.errorf("invalid number %q", t)
+			return new(big.Int)
+		}
+		return p.check(v, "%s", t)
+	case isWord(t[0]):
+		v, ok := p.s.lookup(t)
+		if !ok && !slices.Contains(p.unresolved, t) {
+			p.unresolved = append(p.unresolved, t)
+		}
+		return v
+	default:
+		p.errorf("unexpected %q", t)
+		return new(big.Int)
+	}
+}
@@ -0,0 +1,295 @@
+package yamlnetlink_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/yamlnetlink"
+)
+
+func TestSpecEval(t *testing.T) {
+	s := &yamlnetlink.Spec{
+		Definitions: []yamlnetlink.Definition{
+			{Name: "ALTIFNAMSIZ", Type: yamlnetlink.DefinitionConst, Value: 64},
+			{Name: "FOO", Type: yamlnetlink.DefinitionConst, Value: 4},
+			{Name: "max-queues", Type: yamlnetlink.DefinitionConst, Value: 8},
+			{Name: "mode", Type: yamlnetlink.DefinitionEnum},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want int64
+	}{
+		{expr: "16", want: 16},
+		{expr: "0x10", want: 16},
+		{expr: "GENL_NAMSIZ - 1", want: 15},
+		{expr: "IFNAMSIZ", want: 16},
+		// Definitions take precedence over well-known constants.
+		{expr: "ALTIFNAMSIZ - 1", want: 63},
+		{expr: "FOO-1", want: 3},
+		{expr: "GENL_NAMSIZ-1", want: 15},
+		{expr: "128-1", want: 127},
+		{expr: "max-queues * 2", want: 16},
+		{expr: "max-queues-1", want: 7},
+		{expr: "max-queues * (2 + 1)", want: 24},
+		{expr: "1 + 2 * 3", want: 7},
+		{expr: "1 << 4 | 1", want: 17},
+		{expr: "-1 + 10 % 4", want: 1},
+		{expr: "^0", want: -1},
+		{expr: "u8-max", want: math.MaxUint8},
+		{expr: "u32-max", want: math.MaxUint32},
+		{expr: "u64-min", want: 0},
+		{expr: "s16-min", want: math.MinInt16},
+		{expr: "s32-max", want: math.MaxInt32},
+		{expr: "s64-max", want: math.MaxInt64},
+		{expr: "s64-min", want: math.MinInt64},
+		// Intermediate results may exceed math.MaxInt64.
+		{expr: "u64-max >> 1", want: math.MaxInt64},
+		{expr: "u64-max - s64-max - 1", want: math.MaxInt64},
+		{expr: "10 / -3", want: -3},
+		{expr: "-10 % 3", want: -1},
+		{expr: "-16 >> 2", want: -4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := s.Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("failed to evaluate: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("unexpected value (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSpecEvalUint(t *testing.T) {
+	var s yamlnetlink.Spec
+
+	tests := []struct {
+		expr string
+		want uint64
+		ok   bool
+	}{
+		{expr: "u64-max", want: math.MaxUint64, ok: true},
+		{expr: "s64-max + 1", want: 1 << 63, ok: true},
+		{expr: "u32-max << 32", want: math.MaxUint32 << 32, ok: true},
+		{expr: "-1"},
+		{expr: "u64-max + 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := s.EvalUint(tt.expr)
+			if tt.ok && err != nil {
+				t.Fatalf("failed to evaluate: %v", err)
+			}
+			if !tt.ok {
+				var eerr *yamlnetlink.ExprError
+				if !errors.As(err, &eerr) {
+					t.Fatalf("expected ExprError, but got: %v", err)
+				}
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("unexpected value (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSpecEvalErrors(t *testing.T) {
+	s := &yamlnetlink.Spec{
+		Definitions: []yamlnetlink.Definition{
+			{Name: "mode", Type: yamlnetlink.DefinitionEnum},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want *yamlnetlink.ExprError
+	}{
+		{
+			expr: "FOO - 1",
+			want: &yamlnetlink.ExprError{Unresolved: []string{"FOO"}},
+		},
+		{
+			// Only const definitions are constants.
+			expr: "mode + BAR * BAR",
+			want: &yamlnetlink.ExprError{Unresolved: []string{"mode", "BAR"}},
+		},
+		{
+			// Hyphens do not join uppercase names.
+			expr: "FOO-1",
+			want: &yamlnetlink.ExprError{Unresolved: []string{"FOO"}},
+		},
+		{
+			expr: "",
+			want: &yamlnetlink.ExprError{Message: "empty expression"},
+		},
+		{
+			expr: "1 +",
+			want: &yamlnetlink.ExprError{Message: "unexpected end of expression"},
+		},
+		{
+			expr: "(1 + 2",
+			want: &yamlnetlink.ExprError{Message: "missing )"},
+		},
+		{
+			expr: "1 2",
+			want: &yamlnetlink.ExprError{Message: `unexpected "2"`},
+		},
+		{
+			expr: "1 / (2 - 2)",
+			want: &yamlnetlink.ExprError{Message: "division by zero"},
+		},
+		{
+			expr: "1 << 64",
+			want: &yamlnetlink.ExprError{Message: "invalid shift count 64"},
+		},
+		{
+			expr: "u64-max",
+			want: &yamlnetlink.ExprError{Message: "value 18446744073709551615 overflows int64"},
+		},
+		{
+			expr: "s64-max + 1",
+			want: &yamlnetlink.ExprError{Message: "value 9223372036854775808 overflows int64"},
+		},
+		{
+			expr: "u64-max + 1",
+			want: &yamlnetlink.ExprError{Message: "18446744073709551615 + 1 overflows 64 bits"},
+		},
+		{
+			expr: "s64-min - 1",
+			want: &yamlnetlink.ExprError{Message: "-9223372036854775808 - 1 overflows 64 bits"},
+		},
+		{
+			expr: "1 << 63 << 1",
+			want: &yamlnetlink.ExprError{Message: "9223372036854775808 << 1 overflows 64 bits"},
+		},
+		{
+			expr: "0x10000000000000000",
+			want: &yamlnetlink.ExprError{Message: "0x10000000000000000 overflows 64 bits"},
+		},
+		{
+			expr: "1.5",
+			want: &yamlnetlink.ExprError{Message: `unexpected '.'`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := s.Eval(tt.expr)
+
+			var got *yamlnetlink.ExprError
+			if !errors.As(err, &got) {
+				t.Fatalf("expected ExprError, but got: %v", err)
+			}
+
+			tt.want.Expr = tt.expr
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("unexpected ExprError (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSpecValidateExpressions(t *testing.T) {
+	s, err := yamlnetlink.Parse(strings.NewReader(`
+name: test
+definitions:
+  - { name: hdr, type: struct, members: [ { name: name, type: binary, len: NAMSIZ } ] }
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: name, type: string, len: GENL_NAMSIZ - 1 }
+      - { name: alias, type: string, checks: { max-len: IFALIASZ - } }
+      - { name: prio, type: u8, checks: { min: 1, max: u8-maximum } }
+      - { name: mtu, type: u32, checks: { max: MAX_MTU + } }
+`))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	var got yamlnetlink.ValidationErrors
+	if err := s.Validate(); !errors.As(err, &got) {
+		t.Fatalf("expected ValidationErrors, but got: %v", err)
+	}
+
+	// Syntax errors are reported even if the expression also contains
+	// unresolved names.
+	want := yamlnetlink.ValidationErrors{
+		{
+			Path:    "attribute-sets[0].attributes[1].checks.max-len",
+			Line:    10,
+			Column:  57,
+			Message: `invalid expression "IFALIASZ -": unexpected end of expression`,
+		},
+		{
+			Path:    "attribute-sets[0].attributes[3].checks.max",
+			Line:    12,
+			Column:  48,
+			Message: `invalid expression "MAX_MTU +": unexpected end of expression`,
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected ValidationErrors (-want +got):\n%s", diff)
+	}
+}
+
+func TestSpecUnresolved(t *testing.T) {
+	s, err := yamlnetlink.Parse(strings.NewReader(`
+name: test
+definitions:
+  - { name: hdr, type: struct, members: [ { name: name, type: binary, len: NAMSIZ } ] }
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: name, type: string, len: GENL_NAMSIZ - 1 }
+      - { name: prio, type: u8, checks: { min: 1, max: u8-maximum } }
+`))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	// Unresolved names are not fatal.
+	fam, err := s.Resolve()
+	if err != nil {
+		t.Fatalf("failed to resolve spec: %v", err)
+	}
+
+	want := yamlnetlink.ValidationErrors{
+		{
+			Path:    "definitions[0].members[0].len",
+			Line:    4,
+			Column:  76,
+			Message: `expression "NAMSIZ": unresolved name "NAMSIZ"`,
+		},
+		{
+			Path:    "attribute-sets[0].attributes[1].checks.max",
+			Line:    10,
+			Column:  56,
+			Message: `expression "u8-maximum": unresolved name "u8-maximum"`,
+		},
+	}
+
+	if diff := cmp.Diff(want, s.Unresolved()); diff != "" {
+		t.Fatalf("unexpected unresolved names (-want +got):\n%s", diff)
+	}
+
+	// The unresolved bound is left unset.
+	wantLimits := yamlnetlink.Limits{Min: yamlnetlink.Limit{Value: 1, Set: true}}
+	if diff := cmp.Diff(wantLimits, fam.AttributeSet("main").Attribute("prio").Limits()); diff != "" {
+		t.Fatalf("unexpected limits (-want +got):\n%s", diff)
+	}
+}
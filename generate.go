@@ -16,6 +16,12 @@ type Config struct {
 	// Package specifies an optional package name for the generated code. If
 	// unset, the default is to use the Spec.Name field.
 	Package string
+
+	// UseUnix specifies that the generated code should refer to the attribute
+	// type and command constants in golang.org/x/sys/unix, rather than
+	// generating its own constants from the Spec. This requires that package
+	// unix carries constants for the family.
+	UseUnix bool
 }
 
 // Generate generates formatted Go code from a YAML netlink Spec. If cfg is nil,
@@ -28,42 +34,50 @@ func Generate(s *Spec, cfg *Config) ([]byte, error) {
 		cfg.Package = s.Name
 	}
 
+	f, err := s.Resolve()
+	if err != nil {
+		return nil, err
+	}
+
 	var b bytes.Buffer
-	g := newGenerator(s, &b)
+	g := newGenerator(f, &b)
+	g.unix = cfg.UseUnix
 
 	g.header(cfg.Package)
 	g.conn()
+	if !g.unix {
+		g.consts()
+	}
+	g.definitions()
 
-	for _, op := range s.Operations.List {
-		g.op(op)
+	for _, op := range f.Operations() {
+		if err := g.op(op); err != nil {
+			return nil, err
+		}
 	}
 
 	return format.Source(b.Bytes())
 }
 
-// A generator generates code from a Spec and writes it to w.
+// A generator generates code from a resolved Spec and writes it to w.
 type generator struct {
 	s *Spec
+	f *Family
 	w io.Writer
 
-	// An index of attribute set names to AttributeSets.
-	asIndex map[string]AttributeSet
-
 	// A set of structs which have already been generated.
 	seenStructs map[string]struct{}
+
+	// Whether to use constants from package unix.
+	unix bool
 }
 
 // newGenerator creates a generator which outputs to w.
-func newGenerator(s *Spec, w io.Writer) *generator {
-	asIndex := make(map[string]AttributeSet)
-	for _, as := range s.AttributeSets {
-		asIndex[as.Name] = as
-	}
-
+func newGenerator(f *Family, w io.Writer) *generator {
 	return &generator{
-		s:           s,
+		s:           f.Spec(),
+		f:           f,
 		w:           w,
-		asIndex:     asIndex,
 		seenStructs: make(map[string]struct{}),
 	}
 }
@@ -72,18 +86,28 @@ func newGenerator(s *Spec, w io.Writer) *generator {
 func (g *generator) header(pkg string) {
 	g.pf("// Package %s is generated from a YAML netlink specification for family %q.", pkg, g.s.Name)
 	g.pf("//")
-	g.pf("// Description: %s", g.s.Doc)
-	g.pf("//")
+	if g.s.Doc != "" {
+		g.doc(g.s.Doc)
+		g.pf("//")
+	}
 	g.pf("// Code generated by yamlnetlink-go. DO NOT EDIT.")
 	g.pf("package %s", pkg)
 	g.pf("")
 
 	g.pf("import (")
 	g.pf(`	"errors"`)
+	if g.needsFmt() {
+		g.pf(`	"fmt"`)
+	}
+	if g.needsStrings() {
+		g.pf(`	"strings"`)
+	}
 	g.pf("")
 	g.pf(`	"github.com/mdlayher/genetlink"`)
 	g.pf(`	"github.com/mdlayher/netlink"`)
-	g.pf(`	"golang.org/x/sys/unix"`)
+	if g.unix {
+		g.pf(`	"golang.org/x/sys/unix"`)
+	}
 	g.pf(")")
 	g.pf("")
 }
@@ -119,28 +143,343 @@ func (g *generator) conn() {
 	g.pf("")
 }
 
+// consts generates constants for the family's attribute types, commands, and
+// multicast group names.
+func (g *generator) consts() {
+	g.pf("// An Attr is a netlink attribute type for family %q.", g.s.Name)
+	g.pf("type Attr uint16")
+	g.pf("")
+
+	for _, rs := range g.f.AttributeSets() {
+		if rs.Parent() != nil {
+			// Subsets share the constants of their parent.
+			continue
+		}
+
+		g.pf("// Attribute types in attribute set %q.", rs.Name())
+		g.pf("const (")
+		for _, ra := range rs.Attributes() {
+			g.pf("%s Attr = %d", g.attrConst(ra), ra.Attribute().Value)
+		}
+		g.pf(")")
+		g.pf("")
+	}
+
+	g.pf("// A Command is a generic netlink command for family %q.", g.s.Name)
+	g.pf("type Command uint8")
+	g.pf("")
+
+	var (
+		ops   = g.s.Operations
+		async = ops.AsyncPrefix
+	)
+	if async == "" {
+		async = ops.NamePrefix
+	}
+
+	g.pf("// Commands.")
+	g.pf("const (")
+	for _, op := range ops.List {
+		if ops.EnumModel != EnumModelDirectional {
+			g.pf("%s Command = %d", camelCase(ops.NamePrefix+op.Name), op.Value)
+			continue
+		}
+
+		// Directional families number requests to the kernel separately from
+		// replies and notifications, either of which may be missing.
+		if op.Value != 0 {
+			g.pf("%s Command = %d", camelCase(ops.NamePrefix+op.Name), op.Value)
+		}
+		if op.ReplyValue != 0 {
+			name := async + op.Name
+			if op.Value != 0 {
+				name += "-reply"
+			}
+
+			g.pf("%s Command = %d", camelCase(name), op.ReplyValue)
+		}
+	}
+	g.pf(")")
+	g.pf("")
+
+	if len(g.s.MulticastGroups.List) == 0 {
+		return
+	}
+
+	g.pf("// Multicast group names.")
+	g.pf("const (")
+	for _, mg := range g.s.MulticastGroups.List {
+		name := mg.CDefineName
+		if name == "" {
+			name = g.s.Name + "-mcgrp-" + mg.Name
+		}
+
+		g.pf("%s = %q", camelCase(name), mg.Name)
+	}
+	g.pf(")")
+	g.pf("")
+}
+
+// needsFmt reports whether the generated code uses package fmt.
+func (g *generator) needsFmt() bool {
+	for _, d := range g.s.Definitions {
+		if d.Type == DefinitionEnum || d.Type == DefinitionFlags {
+			return true
+		}
+	}
+
+	return false
+}
+
+// needsStrings reports whether the generated code uses package strings.
+func (g *generator) needsStrings() bool {
+	for _, d := range g.s.Definitions {
+		if d.Type == DefinitionFlags || g.enumAsFlags(d.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// enumAsFlags reports whether any attribute uses the values of the named enum
+// Definition as bit numbers in a bitmask.
+func (g *generator) enumAsFlags(name string) bool {
+	for _, rs := range g.f.AttributeSets() {
+		for _, ra := range rs.Attributes() {
+			if a := ra.Attribute(); a.Enum == name && a.EnumAsFlags {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// definitions generates types for the family's enum and flags definitions.
+func (g *generator) definitions() {
+	for _, d := range g.s.Definitions {
+		switch d.Type {
+		case DefinitionEnum:
+			g.enum(d)
+			if g.enumAsFlags(d.Name) {
+				name := camelCase(d.Name) + "Flags"
+				g.bitmask(d, name, Doc(fmt.Sprintf("A %s is a bitmask of the values of enum %q.", name, d.Name)))
+			}
+		case DefinitionFlags:
+			g.bitmask(d, camelCase(d.Name), d.Doc)
+		}
+	}
+}
+
+// enum generates a type for an enum Definition, with a constant for each
+// entry and methods to convert between values and entry names.
+func (g *generator) enum(d Definition) {
+	name := camelCase(d.Name)
+
+	if d.Doc != "" {
+		g.doc(d.Doc)
+	} else {
+		g.pf("// A %s is a value of enum %q.", name, d.Name)
+	}
+	g.pf("type %s uint32", name)
+	g.pf("")
+
+	g.pf("// Possible %s values.", name)
+	g.pf("const (")
+	for _, e := range d.Entries {
+		g.doc(e.Doc)
+		g.pf("%s%s %s = %d", name, camelCase(e.Name), name, e.Value)
+	}
+	g.pf(")")
+	g.pf("")
+
+	g.pf("// String returns the name of a %s in the specification.", name)
+	g.pf("func (x %s) String() string {", name)
+	g.pf("switch x {")
+	for _, e := range d.Entries {
+		g.pf("case %s%s:", name, camelCase(e.Name))
+		g.pf("	return %q", e.Name)
+	}
+	g.pf("}")
+	g.pf("")
+	g.pf(`return fmt.Sprintf("%s(%%d)", uint32(x))`, name)
+	g.pf("}")
+	g.pf("")
+
+	g.pf("// Parse%s returns the %s named s in the specification.", name, name)
+	g.pf("func Parse%s(s string) (%s, error) {", name, name)
+	g.pf("switch s {")
+	for _, e := range d.Entries {
+		g.pf("case %q:", e.Name)
+		g.pf("	return %s%s, nil", name, camelCase(e.Name))
+	}
+	g.pf("}")
+	g.pf("")
+	g.pf(`return 0, fmt.Errorf("%s: unknown %s %%q", s)`, g.s.Name, name)
+	g.pf("}")
+	g.pf("")
+}
+
+// bitmask generates a bitmask type for a flags Definition or an enum
+// Definition whose values are used as bit numbers, with a constant for each
+// entry and methods to manipulate and print the flags.
+func (g *generator) bitmask(d Definition, name string, doc Doc) {
+	// Use a wider type if any bit does not fit in 32 bits.
+	typ := "uint32"
+	for _, e := range d.Entries {
+		if e.Value >= 32 {
+			typ = "uint64"
+		}
+	}
+
+	if doc != "" {
+		g.doc(doc)
+	} else {
+		g.pf("// A %s is a bitmask of flags %q.", name, d.Name)
+	}
+	g.pf("type %s %s", name, typ)
+	g.pf("")
+
+	g.pf("// Possible %s flags.", name)
+	g.pf("const (")
+	for _, e := range d.Entries {
+		g.doc(e.Doc)
+		g.pf("%s%s %s = 1 << %d", name, camelCase(e.Name), name, e.Value)
+	}
+	g.pf(")")
+	g.pf("")
+
+	g.pf("// Has reports whether all of the flags in f are set in x.")
+	g.pf("func (x %s) Has(f %s) bool { return x&f == f }", name, name)
+	g.pf("")
+	g.pf("// Set sets the flags in f.")
+	g.pf("func (x *%s) Set(f %s) { *x |= f }", name, name)
+	g.pf("")
+	g.pf("// Clear clears the flags in f.")
+	g.pf("func (x *%s) Clear(f %s) { *x &^= f }", name, name)
+	g.pf("")
+
+	g.pf("// String returns the names of the flags set in x, separated by |. Bits")
+	g.pf("// which do not correspond to a flag are printed in hexadecimal.")
+	g.pf("func (x %s) String() string {", name)
+	g.pf("var names []string")
+	for _, e := range d.Entries {
+		c := name + camelCase(e.Name)
+
+		g.pf("if x&%s != 0 {", c)
+		g.pf("	names = append(names, %q)", e.Name)
+		g.pf("	x &^= %s", c)
+		g.pf("}")
+	}
+	g.pf("if x != 0 || len(names) == 0 {")
+	g.pf(`	names = append(names, fmt.Sprintf("%%#x", %s(x)))`, typ)
+	g.pf("}")
+	g.pf("")
+	g.pf(`return strings.Join(names, "|")`)
+	g.pf("}")
+	g.pf("")
+}
+
+// enumType returns the name of the generated type for an unsigned integer
+// attribute which refers to an enum or flags Definition, or the empty string
+// if it does not.
+func (g *generator) enumType(ra *ResolvedAttribute) string {
+	switch ra.Attribute().Type {
+	case "u8", "u16", "u32", "u64":
+	default:
+		return ""
+	}
+
+	d := ra.Enum()
+	switch {
+	case d == nil:
+		return ""
+	case d.Type == DefinitionEnum && ra.Attribute().EnumAsFlags:
+		return camelCase(d.Name) + "Flags"
+	default:
+		return camelCase(d.Name)
+	}
+}
+
+// attrConst returns the name of the constant for an attribute's type.
+func (g *generator) attrConst(ra *ResolvedAttribute) string {
+	name := ra.Set().NamePrefix() + ra.Attribute().Name
+	if g.unix {
+		return unixConst(name)
+	}
+
+	return camelCase(name)
+}
+
+// attrType returns an expression which converts the constant for an
+// attribute's type to the uint16 used by package netlink.
+func (g *generator) attrType(ra *ResolvedAttribute) string {
+	if g.unix {
+		return g.attrConst(ra)
+	}
+
+	return "uint16(" + g.attrConst(ra) + ")"
+}
+
+// adType returns an expression for the type of the attribute being decoded,
+// which is compared against the constants for attribute types.
+func (g *generator) adType() string {
+	if g.unix {
+		return "ad.Type()"
+	}
+
+	return "Attr(ad.Type())"
+}
+
+// command returns an expression for the command ID of an Operation's
+// requests.
+func (g *generator) command(op *Operation) string {
+	name := g.s.Operations.NamePrefix + op.Name
+	if g.unix {
+		return unixConst(name)
+	}
+
+	return "uint8(" + camelCase(name) + ")"
+}
+
 // op begins generating code for the input Operation.
-func (g *generator) op(op Operation) {
+func (g *generator) op(rop *ResolvedOperation) error {
+	op := rop.Operation()
+
 	// Only generate operations where either the request or response has at
 	// least one attribute.
-	var gss []gstruct
+	var dods []doOrDump
 	if len(op.Do.Request.Attributes) > 0 || len(op.Do.Reply.Attributes) > 0 {
-		gss = append(gss, g.opStruct(op, doOp, doRequest))
-		gss = append(gss, g.opStruct(op, doOp, doReply))
-		g.method(op, doOp)
+		dods = append(dods, doOp)
 	}
-
 	if len(op.Dump.Request.Attributes) > 0 || len(op.Dump.Reply.Attributes) > 0 {
-		gss = append(gss, g.opStruct(op, dumpOp, doRequest))
-		gss = append(gss, g.opStruct(op, dumpOp, doReply))
-		g.method(op, dumpOp)
+		dods = append(dods, dumpOp)
+	}
+
+	var gss []gstruct
+	for _, dod := range dods {
+		for _, ror := range []requestOrReply{doRequest, doReply} {
+			gs, err := g.opStruct(rop, dod, ror)
+			if err != nil {
+				return err
+			}
+			gss = append(gss, gs)
+		}
+
+		if err := g.method(rop, dod); err != nil {
+			return err
+		}
 	}
 
 	g.structs(gss)
+	return nil
 }
 
 // method generates a Do or Dump method for an Operation.
-func (g *generator) method(op Operation, dod doOrDump) {
+func (g *generator) method(rop *ResolvedOperation, dod doOrDump) error {
+	op := rop.Operation()
+
 	var (
 		slice, flags string
 		oas          OperationAttributes
@@ -164,7 +503,7 @@ func (g *generator) method(op Operation, dod doOrDump) {
 		s := dod.String() + camelCase(op.Name)
 
 		g.pf("// %s wraps the %q operation:", s, op.Name)
-		g.pf("// %s", op.Doc)
+		g.doc(op.Doc)
 
 		// If there are no request attributes, generate no parameter names.
 		var params string
@@ -182,12 +521,14 @@ func (g *generator) method(op Operation, dod doOrDump) {
 	}
 
 	// Generate the attribute encoder for arguments.
-	g.encoder(op, oas.Request, hasReply)
+	if err := g.encoder(rop, oas.Request, hasReply); err != nil {
+		return err
+	}
 
 	// Use packed arguments in a genetlink message body to execute a command.
 	g.pf("msg := genetlink.Message{")
 	g.pf("	Header: genetlink.Header{")
-	g.pf("		Command: %s,", unixConst(g.s.Operations.NamePrefix+op.Name))
+	g.pf("		Command: %s,", g.command(op))
 	g.pf("		Version: c.f.Version,")
 	g.pf("	},")
 	g.pf("	Data: b,")
@@ -201,7 +542,7 @@ func (g *generator) method(op Operation, dod doOrDump) {
 		g.pf("return err")
 		g.pf("}")
 		g.pf("")
-		return
+		return nil
 	}
 
 	g.pf("msgs, err := c.c.Execute(msg, c.f.ID, %s)", flags)
@@ -211,10 +552,13 @@ func (g *generator) method(op Operation, dod doOrDump) {
 	g.pf("")
 
 	// Generate an attribute decoder for outputs.
-	g.decoder(op, dod)
+	if err := g.decoder(rop, dod); err != nil {
+		return err
+	}
 
 	g.pf("}")
 	g.pf("")
+	return nil
 }
 
 // structs generates code for struct definitions.
@@ -227,13 +571,11 @@ func (g *generator) structs(gss []gstruct) {
 			return
 		}
 
-		g.pf("// %s", gs.Doc)
+		g.doc(gs.Doc)
 		g.pf("type %s struct {", gs.Name)
 
 		for _, f := range gs.Fields {
-			if f.Doc != "" {
-				g.pf("// %s", f.Doc)
-			}
+			g.doc(f.Doc)
 
 			if f.TODO {
 				g.pf("// TODO: field %q, type %q", f.Name, f.Type)
@@ -259,24 +601,28 @@ func (g *generator) structs(gss []gstruct) {
 
 // A gstruct is a struct which will be generated.
 type gstruct struct {
-	Name, Doc string
-	Fields    []field
-	Nested    []gstruct
+	Name   string
+	Doc    Doc
+	Fields []field
+	Nested []gstruct
 }
 
 // A field is a gstruct field.
 type field struct {
-	Name, Type, Doc string
-	TODO            bool
+	Name, Type string
+	Doc        Doc
+	TODO       bool
 }
 
 // opStruct generates a struct for an Operation. Different attribute sets are
 // used depending on the values for Do/Dump and Request/Reply.
 func (g *generator) opStruct(
-	op Operation,
+	rop *ResolvedOperation,
 	dod doOrDump,
 	ror requestOrReply,
-) gstruct {
+) (gstruct, error) {
+	op := rop.Operation()
+
 	// Narrow down which list of attributes we'll be generating from.
 	var list OperationAttributesList
 	{
@@ -303,29 +649,34 @@ func (g *generator) opStruct(
 
 	if len(list.Attributes) == 0 {
 		// The chosen list has no attributes, don't generate a struct.
-		return gstruct{}
+		return gstruct{}, nil
 	}
 
 	gs := gstruct{
 		Name: fullName,
-		Doc:  fmt.Sprintf("%s is used with the %s method.", fullName, opName),
+		Doc:  Doc(fmt.Sprintf("%s is used with the %s method.", fullName, opName)),
 	}
 
 	// Walk the struct's attributes to get its fields and nested structs.
-	gs.Fields, gs.Nested = g.walkAttributes(g.attrs(op.AttributeSet, list.Attributes))
+	var err error
+	gs.Fields, gs.Nested, err = g.walkAttributes(rop.Attributes(list))
+	if err != nil {
+		return gstruct{}, err
+	}
 
-	return gs
+	return gs, nil
 }
 
 // walkAttributes generates a set of fields and nested structs for a given set
 // of attributes.
-func (g *generator) walkAttributes(attrs []Attribute) ([]field, []gstruct) {
+func (g *generator) walkAttributes(attrs []*ResolvedAttribute) ([]field, []gstruct, error) {
 	var (
 		fields []field
 		gss    []gstruct
 	)
 
-	for _, a := range attrs {
+	for _, ra := range attrs {
+		a := ra.Attribute()
 		var (
 			typ          string
 			nested, todo bool
@@ -340,6 +691,16 @@ func (g *generator) walkAttributes(attrs []Attribute) ([]field, []gstruct) {
 			typ = "uint32"
 		case "u64":
 			typ = "uint64"
+		case "s8":
+			typ = "int8"
+		case "s16":
+			typ = "int16"
+		case "s32":
+			typ = "int32"
+		case "s64":
+			typ = "int64"
+		case "flag":
+			typ = "bool"
 		case "nul-string":
 			typ = "string"
 		case "nest":
@@ -353,6 +714,10 @@ func (g *generator) walkAttributes(attrs []Attribute) ([]field, []gstruct) {
 			todo = true
 		}
 
+		if et := g.enumType(ra); et != "" {
+			typ = et
+		}
+
 		if nested {
 			// For nested types, we must walk the attribute set of the nested
 			// type and generate structs and fields as needed.
@@ -361,11 +726,19 @@ func (g *generator) walkAttributes(attrs []Attribute) ([]field, []gstruct) {
 			if a.Doc != "" {
 				gs.Doc = a.Doc
 			} else {
-				gs.Doc = fmt.Sprintf("%s contains nested netlink attributes.", gs.Name)
+				gs.Doc = Doc(fmt.Sprintf("%s contains nested netlink attributes.", gs.Name))
+			}
+
+			rs, err := nestedSet(ra)
+			if err != nil {
+				return nil, nil, err
 			}
 
 			// Fetch fields and nested structs for entire set.
-			gs.Fields, gs.Nested = g.walkAttributes(g.attrs(a.NestedAttributes, nil))
+			gs.Fields, gs.Nested, err = g.walkAttributes(rs.Attributes())
+			if err != nil {
+				return nil, nil, err
+			}
 
 			gss = append(gss, gs)
 		}
@@ -378,24 +751,26 @@ func (g *generator) walkAttributes(attrs []Attribute) ([]field, []gstruct) {
 		})
 	}
 
-	return fields, gss
+	return fields, gss, nil
 }
 
 // encoder generates a netlink attribute encoder for a set of attribute
 // arguments for a command.
-func (g *generator) encoder(op Operation, list OperationAttributesList, addNil bool) {
+func (g *generator) encoder(rop *ResolvedOperation, list OperationAttributesList, addNil bool) error {
 	if len(list.Attributes) == 0 {
 		// Shortcut.
 		g.pf("// No attribute arguments.")
 		g.pf("var b []byte")
 		g.pf("")
-		return
+		return nil
 	}
 
 	g.pf("ae := netlink.NewAttributeEncoder()")
 
 	// Begin generating switch cases to decode into receiver.
-	g.encoderCases("req", op.AttributeSet, g.attrs(op.AttributeSet, list.Attributes))
+	if err := g.encoderCases("req", rop.Attributes(list)); err != nil {
+		return err
+	}
 
 	// Finally pack the attributes.
 	g.pf("")
@@ -411,21 +786,36 @@ func (g *generator) encoder(op Operation, list OperationAttributesList, addNil b
 
 	g.pf("}")
 	g.pf("")
+	return nil
 }
 
-func (g *generator) encoderCases(receiver, aset string, attrs []Attribute) {
-	for _, a := range attrs {
-		// Use the unix package const for each type, and field to fill in the
-		// arguments that are non-zero.
+func (g *generator) encoderCases(receiver string, attrs []*ResolvedAttribute) error {
+	for _, ra := range attrs {
+		// Use the const for each type, and field to fill in the arguments
+		// that are non-zero.
 		var (
-			typ = unixConst(g.attrPrefix(aset) + a.Name)
+			a   = ra.Attribute()
+			typ = g.attrType(ra)
 			f   = receiver + "." + camelCase(a.Name)
 		)
 
-		// mkUint generates a uint* case.
+		// mkUint generates a uint* case, converting enum types to the
+		// attribute's width.
 		mkUint := func(bits int) {
+			v := f
+			if g.enumType(ra) != "" {
+				v = fmt.Sprintf("uint%d(%s)", bits, f)
+			}
+
+			g.pf("if %s != 0 {", f)
+			g.pf("	ae.Uint%d(%s, %s)", bits, typ, v)
+			g.pf("}")
+		}
+
+		// mkInt generates an int* case.
+		mkInt := func(bits int) {
 			g.pf("if %s != 0 {", f)
-			g.pf("	ae.Uint%d(%s, %s)", bits, typ, f)
+			g.pf("	ae.Int%d(%s, %s)", bits, typ, f)
 			g.pf("}")
 		}
 
@@ -438,14 +828,33 @@ func (g *generator) encoderCases(receiver, aset string, attrs []Attribute) {
 			mkUint(32)
 		case "u64":
 			mkUint(64)
+		case "s8":
+			mkInt(8)
+		case "s16":
+			mkInt(16)
+		case "s32":
+			mkInt(32)
+		case "s64":
+			mkInt(64)
+		case "flag":
+			g.pf("if %s {", f)
+			g.pf("	ae.Flag(%s, true)", typ)
+			g.pf("}")
 		case "nul-string":
 			g.pf(`if %s != "" {`, f)
 			g.pf("	ae.String(%s, %s)", typ, f)
 			g.pf("}")
 		case "nest":
+			rs, err := nestedSet(ra)
+			if err != nil {
+				return err
+			}
+
 			g.pf("ae.Nested(%s, func(ae *netlink.AttributeEncoder) error {", typ)
 
-			g.encoderCases(f, a.NestedAttributes, g.attrs(a.NestedAttributes, nil))
+			if err := g.encoderCases(f, rs.Attributes()); err != nil {
+				return err
+			}
 
 			g.pf("")
 			g.pf("	return nil")
@@ -454,11 +863,14 @@ func (g *generator) encoderCases(receiver, aset string, attrs []Attribute) {
 			g.pf("	// TODO: field %q, type %q", f, a.Type)
 		}
 	}
+
+	return nil
 }
 
 // decoder generates a netlink attribute decoder loop to to iterate over reply
 // messages from a Do or Dump.
-func (g *generator) decoder(op Operation, dod doOrDump) {
+func (g *generator) decoder(rop *ResolvedOperation, dod doOrDump) error {
+	op := rop.Operation()
 	name := dod.String() + camelCase(op.Name) + doReply.String()
 
 	// Preallocate replies and range over all inputs, decoding each.
@@ -483,10 +895,12 @@ func (g *generator) decoder(op Operation, dod doOrDump) {
 	}
 
 	g.pf("for ad.Next() {")
-	g.pf("	switch ad.Type() {")
+	g.pf("	switch %s {", g.adType())
 
 	// Begin generating switch cases to decode into receiver.
-	g.decoderCases(receiver, op.AttributeSet, g.attrs(op.AttributeSet, oas.Attributes))
+	if err := g.decoderCases(receiver, rop.Attributes(oas)); err != nil {
+		return err
+	}
 
 	g.pf("	}")
 	g.pf("}")
@@ -513,20 +927,34 @@ func (g *generator) decoder(op Operation, dod doOrDump) {
 	case dumpOp:
 		g.pf("return replies, nil")
 	}
+
+	return nil
 }
 
-func (g *generator) decoderCases(receiver, aset string, attrs []Attribute) {
+func (g *generator) decoderCases(receiver string, attrs []*ResolvedAttribute) error {
 	// Begin generating switch cases.
-	for _, a := range attrs {
-		// Use the unix package const for each type, and field to fill in the
-		// arguments that are non-zero.
+	for _, ra := range attrs {
+		// Use the const for each type, and field to fill in the arguments
+		// that are non-zero.
 		var (
-			typ   = unixConst(g.attrPrefix(aset) + a.Name)
+			a     = ra.Attribute()
+			typ   = g.attrConst(ra)
 			field = receiver + "." + camelCase(a.Name)
 		)
 
-		// mkUint generates a uint* case.
-		mkUint := func(bits int) { g.pf("%s = ad.Uint%d()", field, bits) }
+		// mkUint generates a uint* case, converting to an enum type if
+		// necessary.
+		mkUint := func(bits int) {
+			if et := g.enumType(ra); et != "" {
+				g.pf("%s = %s(ad.Uint%d())", field, et, bits)
+				return
+			}
+
+			g.pf("%s = ad.Uint%d()", field, bits)
+		}
+
+		// mkInt generates an int* case.
+		mkInt := func(bits int) { g.pf("%s = ad.Int%d()", field, bits) }
 
 		g.pf("case %s:", typ)
 		switch a.Type {
@@ -538,18 +966,31 @@ func (g *generator) decoderCases(receiver, aset string, attrs []Attribute) {
 			mkUint(32)
 		case "u64":
 			mkUint(64)
+		case "s8":
+			mkInt(8)
+		case "s16":
+			mkInt(16)
+		case "s32":
+			mkInt(32)
+		case "s64":
+			mkInt(64)
+		case "flag":
+			g.pf("%s = ad.Flag()", field)
 		case "nul-string":
 			g.pf("%s = ad.String()", field)
 		case "nest":
+			rs, err := nestedSet(ra)
+			if err != nil {
+				return err
+			}
+
 			g.pf("ad.Nested(func(ad *netlink.AttributeDecoder) error {")
 			g.pf("	for ad.Next() {")
-			g.pf("		switch ad.Type() {")
+			g.pf("		switch %s {", g.adType())
 
-			g.decoderCases(
-				receiver+"."+camelCase(a.Name),
-				a.NestedAttributes,
-				g.attrs(a.NestedAttributes, nil),
-			)
+			if err := g.decoderCases(receiver+"."+camelCase(a.Name), rs.Attributes()); err != nil {
+				return err
+			}
 
 			g.pf("		}")
 			g.pf("	}")
@@ -562,19 +1003,22 @@ func (g *generator) decoderCases(receiver, aset string, attrs []Attribute) {
 			// same name "ad" since the switch cases are hardcoded.
 			const tmp = "nest"
 
+			rs, err := nestedSet(ra)
+			if err != nil {
+				return err
+			}
+
 			g.pf("ad.Nested(func(arr *netlink.AttributeDecoder) error {")
 			g.pf("	%s = make([]%s, 0, arr.Len())", field, camelCase(a.NestedAttributes))
 			g.pf("	for arr.Next() {")
 			g.pf("		arr.Nested(func(ad *netlink.AttributeDecoder) error {")
 			g.pf("			var %s %s", tmp, camelCase(a.NestedAttributes))
 			g.pf("			for ad.Next() {")
-			g.pf("				switch ad.Type() {")
+			g.pf("				switch %s {", g.adType())
 
-			g.decoderCases(
-				tmp,
-				a.NestedAttributes,
-				g.attrs(a.NestedAttributes, nil),
-			)
+			if err := g.decoderCases(tmp, rs.Attributes()); err != nil {
+				return err
+			}
 
 			g.pf("				}")
 			g.pf("			}")
@@ -591,48 +1035,30 @@ func (g *generator) decoderCases(receiver, aset string, attrs []Attribute) {
 			g.pf("	// TODO: field %q, type %q", field, a.Type)
 		}
 	}
-}
 
-// attrs generates a list of wanted attributes given an attribute set and the
-// names of the attributes that are expected.
-func (g *generator) attrs(aset string, list []string) []Attribute {
-	if aset == "" {
-		panic("empty attribute set")
-	}
-
-	if len(list) == 0 {
-		return g.asIndex[aset].Attributes
-	}
-
-	idx := make(map[string]Attribute)
-	for _, a := range g.asIndex[aset].Attributes {
-		idx[a.Name] = a
-	}
-
-	var as []Attribute
-	for _, l := range list {
-		if attr, ok := idx[l]; ok {
-			as = append(as, attr)
-		}
-	}
+	return nil
+}
 
-	if len(as) == 0 {
-		panicf("found no attributes for set %q in list %v", aset, list)
+// nestedSet returns the attribute set nested within a nest or array-nest
+// attribute, or an error if the attribute has no nested-attributes.
+func nestedSet(ra *ResolvedAttribute) (*ResolvedAttributeSet, error) {
+	if rs := ra.Nested(); rs != nil {
+		return rs, nil
 	}
 
-	return as
+	a := ra.Attribute()
+	return nil, fmt.Errorf("yamlnetlink: %s attribute %q in set %q has no nested-attributes",
+		a.Type, a.Name, ra.Set().Name())
 }
 
-// attrPrefix returns the prefix for an attribute based on its AttributeSet
-// name.
-func (g *generator) attrPrefix(aset string) string {
-	if p := g.asIndex[aset].NamePrefix; p != "" {
-		// Specific prefix.
-		return p
-	}
+// docWidth is the width at which generated comments are wrapped.
+const docWidth = 80
 
-	// Default.
-	return fmt.Sprintf("%s-a-%s-", g.s.Name, aset)
+// doc writes a Doc as a comment, if it is not empty.
+func (g *generator) doc(d Doc) {
+	for _, l := range d.comment(docWidth) {
+		g.pf("%s", l)
+	}
 }
 
 // pf is short for "printf" and writes formatted data to g.w. All format strings
@@ -704,7 +1130,3 @@ func unixConst(s string) string {
 func title(s string) string {
 	return cases.Title(language.AmericanEnglish).String(s)
 }
-
-func panicf(format string, a ...interface{}) {
-	panic(fmt.Sprintf(format, a...))
-}
@@ -2,9 +2,12 @@ package yamlnetlink_test
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -62,6 +65,464 @@ func TestGenerateEthtool(t *testing.T) {
 	// TODO!
 }
 
+func TestGenerateSigned(t *testing.T) {
+	out := generate(t, "signed")
+
+	type reply struct {
+		S8  int8
+		S16 int16
+		S32 int32
+		S64 int64
+	}
+
+	var got reply
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Logf("stdout: %s", string(out))
+		t.Fatalf("failed to unmarshal reply: %v", err)
+	}
+
+	want := reply{
+		S8:  math.MinInt8,
+		S16: -2,
+		S32: -3,
+		S64: math.MinInt64,
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected reply (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateFlag(t *testing.T) {
+	out := generate(t, "flag")
+
+	type reply struct {
+		Set   bool
+		Unset bool
+	}
+
+	type stdout struct {
+		Reply reply    `json:"reply"`
+		Types []uint16 `json:"types"`
+	}
+
+	var got stdout
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Logf("stdout: %s", string(out))
+		t.Fatalf("failed to unmarshal reply: %v", err)
+	}
+
+	// Unset flags must not be encoded.
+	want := stdout{
+		Reply: reply{Set: true},
+		Types: []uint16{1},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected echo (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateBitmask(t *testing.T) {
+	out := generate(t, "bitmask")
+
+	type stdout struct {
+		Modes       string `json:"modes"`
+		Features    string `json:"features"`
+		Bits        uint64 `json:"bits"`
+		HasHigh     bool   `json:"has_high"`
+		HasLowHigh  bool   `json:"has_low_high"`
+		NoModes     string `json:"no_modes"`
+		UnknownMode string `json:"unknown_mode"`
+	}
+
+	var got stdout
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Logf("stdout: %s", string(out))
+		t.Fatalf("failed to unmarshal reply: %v", err)
+	}
+
+	// Bit 63 must survive the round trip, and unknown bits are printed in
+	// hexadecimal.
+	want := stdout{
+		Modes:       "10baseT-full|100baseT-full",
+		Features:    "high|0x10000000000",
+		Bits:        1<<63 | 1<<40,
+		HasHigh:     true,
+		HasLowHigh:  false,
+		NoModes:     "0x0",
+		UnknownMode: "100baseT-half|0x80000000",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected echo (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateDocs(t *testing.T) {
+	s, err := yamlnetlink.Parse(strings.NewReader(`
+name: test
+doc: |
+  Test family with a long description which must be wrapped onto more than one
+  line of the generated package comment.
+
+  It also has a second paragraph.
+attribute-sets:
+  -
+    name: main
+    attributes:
+      -
+        name: mode
+        type: u32
+        doc: |
+          The mode:
+          - a: the first mode
+          - b: the second mode
+operations:
+  list:
+    -
+      name: get
+      doc: Get things.
+      attribute-set: main
+      do:
+        request:
+          attributes: [ mode ]
+`))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	b, err := yamlnetlink.Generate(s, nil)
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+
+	for _, want := range []string{
+		`// Test family with a long description which must be wrapped onto more than one
+// line of the generated package comment.
+//
+// It also has a second paragraph.
+//
+`,
+		`	// The mode:
+	//
+	//   - a: the first mode
+	//   - b: the second mode
+	Mode uint32
+`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("generated code does not contain:\n%s\n\n%s", want, b)
+		}
+	}
+}
+
+func TestGenerateConstants(t *testing.T) {
+	s, err := yamlnetlink.Parse(strings.NewReader(`
+name: test
+protocol: genetlink-legacy
+attribute-sets:
+  -
+    name: main
+    attributes:
+      -
+        name: mode
+        type: u32
+      -
+        name: flags
+        value: 5
+        type: u32
+  -
+    name: sub
+    subset-of: main
+    attributes:
+      -
+        name: mode
+operations:
+  enum-model: directional
+  name-prefix: test-msg-
+  list:
+    -
+      name: get
+      attribute-set: main
+      do:
+        request:
+          value: 3
+          attributes: [ mode ]
+        reply:
+          value: 7
+          attributes: [ mode, flags ]
+    -
+      name: ntf
+      notify: get
+mcast-groups:
+  list:
+    -
+      name: monitor
+`))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		cfg       *yamlnetlink.Config
+		want, not []string
+	}{
+		{
+			name: "generated",
+			want: []string{
+				`const (
+	TestAMainMode  Attr = 1
+	TestAMainFlags Attr = 5
+)
+`,
+				`const (
+	TestMsgGet      Command = 3
+	TestMsgGetReply Command = 7
+	TestMsgNtf      Command = 8
+)
+`,
+				`TestMcgrpMonitor = "monitor"`,
+				`ae.Uint32(uint16(TestAMainMode), req.Mode)`,
+				`Command: uint8(TestMsgGet),`,
+				`switch Attr(ad.Type()) {`,
+			},
+			not: []string{
+				`"golang.org/x/sys/unix"`,
+				`Attribute types in attribute set "sub"`,
+			},
+		},
+		{
+			name: "unix",
+			cfg:  &yamlnetlink.Config{UseUnix: true},
+			want: []string{
+				`"golang.org/x/sys/unix"`,
+				`ae.Uint32(unix.TEST_A_MAIN_MODE, req.Mode)`,
+				`Command: unix.TEST_MSG_GET,`,
+				`case unix.TEST_A_MAIN_FLAGS:`,
+			},
+			not: []string{
+				`type Attr uint16`,
+				`type Command uint8`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := yamlnetlink.Generate(s, tt.cfg)
+			if err != nil {
+				t.Fatalf("failed to generate: %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(string(b), want) {
+					t.Fatalf("generated code does not contain:\n%s\n\n%s", want, b)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(string(b), not) {
+					t.Fatalf("generated code must not contain:\n%s\n\n%s", not, b)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateEnums(t *testing.T) {
+	s, err := yamlnetlink.Parse(strings.NewReader(`
+name: test
+protocol: genetlink
+definitions:
+  -
+    type: enum
+    name: port-flavour
+    doc: The flavour of a port.
+    entries:
+      - physical
+      -
+        name: cpu
+        doc: The CPU port.
+      - dsa
+  -
+    type: enum
+    name: link-mode
+    value-start: 10
+    entries: [ 10baseT-half, 10baseT-full ]
+attribute-sets:
+  -
+    name: main
+    attributes:
+      -
+        name: flavour
+        type: u16
+        enum: port-flavour
+      -
+        name: mode
+        type: u32
+        enum: link-mode
+operations:
+  list:
+    -
+      name: get
+      attribute-set: main
+      do:
+        request:
+          attributes: [ flavour ]
+        reply:
+          attributes: [ flavour, mode ]
+`))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	b, err := yamlnetlink.Generate(s, nil)
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+
+	for _, want := range []string{
+		`// The flavour of a port.
+type PortFlavour uint32
+`,
+		`	// The CPU port.
+	PortFlavourCpu PortFlavour = 1
+`,
+		`	LinkMode10BasetHalf LinkMode = 10
+`,
+		`	case LinkMode10BasetFull:
+		return "10baseT-full"
+`,
+		`	return fmt.Sprintf("LinkMode(%d)", uint32(x))`,
+		`	case "dsa":
+		return PortFlavourDsa, nil
+`,
+		`	return 0, fmt.Errorf("test: unknown PortFlavour %q", s)`,
+		`ae.Uint16(uint16(TestAMainFlavour), uint16(req.Flavour))`,
+		`reply.Flavour = PortFlavour(ad.Uint16())`,
+		`reply.Mode = LinkMode(ad.Uint32())`,
+		`	Flavour PortFlavour
+	Mode    LinkMode
+`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("generated code does not contain:\n%s\n\n%s", want, b)
+		}
+	}
+}
+
+func TestGenerateFlags(t *testing.T) {
+	s, err := yamlnetlink.Parse(strings.NewReader(`
+name: test
+protocol: genetlink
+definitions:
+  -
+    type: enum
+    name: link-mode
+    entries: [ 10baseT-half, 10baseT-full ]
+  -
+    type: flags
+    name: big
+    doc: Flags which need 64 bits.
+    entries:
+      - low
+      -
+        name: high
+        value: 40
+attribute-sets:
+  -
+    name: main
+    attributes:
+      -
+        name: modes
+        type: u32
+        enum: link-mode
+        enum-as-flags: true
+      -
+        name: big
+        type: u64
+        enum: big
+operations:
+  list:
+    -
+      name: get
+      attribute-set: main
+      do:
+        request:
+          attributes: [ modes, big ]
+        reply:
+          attributes: [ modes ]
+`))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	b, err := yamlnetlink.Generate(s, nil)
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+
+	for _, want := range []string{
+		`// A LinkModeFlags is a bitmask of the values of enum "link-mode".
+type LinkModeFlags uint32
+`,
+		`	LinkModeFlags10BasetFull LinkModeFlags = 1 << 1
+`,
+		`// Flags which need 64 bits.
+type Big uint64
+`,
+		`	BigHigh Big = 1 << 40
+`,
+		`func (x Big) Has(f Big) bool { return x&f == f }`,
+		`func (x *Big) Set(f Big) { *x |= f }`,
+		`func (x *Big) Clear(f Big) { *x &^= f }`,
+		`	if x&LinkModeFlags10BasetHalf != 0 {
+		names = append(names, "10baseT-half")
+		x &^= LinkModeFlags10BasetHalf
+	}
+`,
+		`		names = append(names, fmt.Sprintf("%#x", uint64(x)))`,
+		`ae.Uint32(uint16(TestAMainModes), uint32(req.Modes))`,
+		`reply.Modes = LinkModeFlags(ad.Uint32())`,
+		`	Modes LinkModeFlags
+	Big   Big
+`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("generated code does not contain:\n%s\n\n%s", want, b)
+		}
+	}
+}
+
+func TestGenerateNestWithoutNestedAttributes(t *testing.T) {
+	s, err := yamlnetlink.Parse(strings.NewReader(`
+name: test
+protocol: genetlink
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: nest, type: nest }
+operations:
+  list:
+    -
+      name: get
+      attribute-set: main
+      do:
+        reply:
+          attributes: [ nest ]
+`))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	var verrs yamlnetlink.ValidationErrors
+	if _, err := yamlnetlink.Generate(s, nil); !errors.As(err, &verrs) {
+		t.Fatalf("expected ValidationErrors, but got: %v", err)
+	}
+}
+
 // generate generates and executes Go code for the specified family using the
 // family's directory under testdata.
 func generate(t *testing.T, family string) []byte {
@@ -4,7 +4,17 @@ go 1.19
 
 require (
 	github.com/google/go-cmp v0.5.9
+	github.com/mdlayher/genetlink v1.3.0
+	github.com/mdlayher/netlink v1.7.0
 	golang.org/x/exp v0.0.0-20221111204811-129d8d6c17ab
 	golang.org/x/text v0.4.0
 	gopkg.in/yaml.v3 v3.0.1
 )
+
+require (
+	github.com/josharian/native v1.0.0 // indirect
+	github.com/mdlayher/socket v0.4.0 // indirect
+	golang.org/x/net v0.2.0 // indirect
+	golang.org/x/sync v0.1.0 // indirect
+	golang.org/x/sys v0.2.0 // indirect
+)
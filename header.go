@@ -0,0 +1,357 @@
+package yamlnetlink
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// HeaderOptions specifies options for ParseHeader.
+type HeaderOptions struct {
+	// Name is the name of the family. If empty, the name is taken from a
+	// string macro in the header whose name ends in _GENL_NAME or
+	// _FAMILY_NAME.
+	Name string
+
+	// UAPIHeader is the path of the header as it is included from C, such as
+	// "linux/ethtool_netlink.h".
+	UAPIHeader string
+}
+
+var (
+	// headerDefine matches a preprocessor definition of a string macro.
+	headerDefine = regexp.MustCompile(`(?m)^[ \t]*#[ \t]*define[ \t]+(\w+)[ \t]+"([^"]*)"`)
+
+	// headerDirective matches a preprocessor directive.
+	headerDirective = regexp.MustCompile(`(?m)^[ \t]*#.*$`)
+
+	// headerEnum matches an enum declaration.
+	headerEnum = regexp.MustCompile(`\benum\s*(\w*)\s*\{([^}]*)\}`)
+)
+
+// ParseHeader parses the enum declarations of a C UAPI header into a skeleton
+// Spec, as a starting point for writing a YAML netlink specification for a
+// family which does not yet have one. If opts is nil, a default HeaderOptions
+// is used. The header is parsed without a C compiler or preprocessor, so
+// macros are not expanded.
+//
+// Enums whose entries share a prefix containing _A_ or _ATTR_, such as
+// ETHTOOL_A_HEADER_, become attribute sets, and an enum whose entries share a
+// prefix containing _CMD_ becomes the operations. Other enums become enum
+// definitions. Name prefixes are computed from the common prefix of each
+// enum's entries, and UNSPEC entries and sentinels such as __ETHTOOL_A_MAX are
+// omitted.
+//
+// Enums with an entry whose value cannot be evaluated, such as one which uses a
+// macro like BIT(n), a cast, or sizeof, are omitted entirely. In that case,
+// ParseHeader returns the Spec for the rest of the header along with
+// HeaderEnumErrors which describe each omitted enum.
+//
+// Attribute types and operation attribute sets cannot be inferred from a
+// header and are left empty, so the Spec will not pass Validate until they
+// are filled in.
+func ParseHeader(r io.Reader, opts *HeaderOptions) (*Spec, error) {
+	if opts == nil {
+		opts = &HeaderOptions{}
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	src := stripComments(strings.ReplaceAll(string(b), "\\\n", " "))
+
+	s := &Spec{
+		Name:       opts.Name,
+		UAPIHeader: opts.UAPIHeader,
+	}
+
+	if s.Name == "" {
+		for _, m := range headerDefine.FindAllStringSubmatch(src, -1) {
+			if strings.HasSuffix(m[1], "_GENL_NAME") || strings.HasSuffix(m[1], "_FAMILY_NAME") {
+				s.Name = m[2]
+				break
+			}
+		}
+	}
+	if s.Name == "" {
+		return nil, fmt.Errorf("yamlnetlink: header does not define a family name, so HeaderOptions.Name must be set")
+	}
+
+	// Enum entries may refer to earlier entries of any enum, so keep their
+	// values as constants for Spec.Eval.
+	consts := &Spec{}
+
+	var errs HeaderEnumErrors
+
+	src = headerDirective.ReplaceAllString(src, "")
+	for _, m := range headerEnum.FindAllStringSubmatch(src, -1) {
+		// Entries which use macros, casts, or sizeof cannot be evaluated, so
+		// skip their enums rather than guessing at their values.
+		entries, err := enumEntries(consts, m[2])
+		if err != nil {
+			err.Enum = m[1]
+			errs = append(errs, err)
+			continue
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		s.headerEnum(m[1], entries)
+	}
+
+	if len(errs) > 0 {
+		return s, errs
+	}
+
+	return s, nil
+}
+
+// A HeaderEnumError describes an enum which ParseHeader omitted because the
+// value of one of its entries could not be evaluated.
+type HeaderEnumError struct {
+	// Enum is the name of the enum, or empty if it is anonymous.
+	Enum string
+
+	// Entry is the name of the entry which could not be evaluated.
+	Entry string
+
+	// Err is the error reported by Spec.Eval for the entry's value.
+	Err *ExprError
+}
+
+// Error implements error.
+func (e *HeaderEnumError) Error() string {
+	enum := "anonymous enum"
+	if e.Enum != "" {
+		enum = "enum " + e.Enum
+	}
+
+	return fmt.Sprintf("yamlnetlink: skipped %s: entry %s: expression %q: %s",
+		enum, e.Entry, e.Err.Expr, e.Err.message())
+}
+
+// Unwrap returns the underlying *ExprError.
+func (e *HeaderEnumError) Unwrap() error { return e.Err }
+
+// HeaderEnumErrors is a list of HeaderEnumErrors returned by ParseHeader.
+type HeaderEnumErrors []*HeaderEnumError
+
+// Error implements error.
+func (es HeaderEnumErrors) Error() string {
+	ss := make([]string, 0, len(es))
+	for _, e := range es {
+		ss = append(ss, e.Error())
+	}
+
+	return strings.Join(ss, "\n")
+}
+
+// A cEnumEntry is an entry of a C enum.
+type cEnumEntry struct {
+	Name  string
+	Value int
+}
+
+// enumEntries parses the entries of the body of a C enum, adding each to
+// consts.
+func enumEntries(consts *Spec, body string) ([]cEnumEntry, *HeaderEnumError) {
+	var (
+		entries []cEnumEntry
+		next    int64
+	)
+
+	for _, e := range strings.Split(body, ",") {
+		name, expr, ok := strings.Cut(e, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if ok {
+			v, err := consts.Eval(cExpr(expr))
+			if err != nil {
+				// Eval always returns *ExprError.
+				return nil, &HeaderEnumError{Entry: name, Err: err.(*ExprError)}
+			}
+
+			next = v
+		}
+
+		entries = append(entries, cEnumEntry{Name: name, Value: int(next)})
+		consts.Definitions = append(consts.Definitions, Definition{
+			Name:  name,
+			Type:  DefinitionConst,
+			Value: int(next),
+		})
+
+		next++
+	}
+
+	return entries, nil
+}
+
+// headerEnum adds the entries of C enum tag to the Spec.
+func (s *Spec) headerEnum(tag string, entries []cEnumEntry) {
+	prefix := commonPrefix(entries)
+
+	// Omit UNSPEC and the sentinels used to compute the maximum value.
+	var items []cEnumEntry
+	for _, e := range entries {
+		name := strings.TrimPrefix(strings.TrimLeft(e.Name, "_"), prefix)
+		switch {
+		case strings.HasPrefix(e.Name, "__"), name == "MAX", name == "CNT":
+			continue
+		case name == "UNSPEC" && e.Value == 0:
+			continue
+		}
+
+		items = append(items, cEnumEntry{Name: kebabCase(name), Value: e.Value})
+	}
+
+	namePrefix := kebabCase(prefix)
+
+	if i := markerIndex(prefix, "_A_", "_ATTR_"); i != -1 {
+		// Attribute sets are named by the remainder of the prefix.
+		name := kebabCase(strings.Trim(prefix[i:], "_"))
+		if name == "" {
+			name = "main"
+		}
+
+		as := AttributeSet{Name: name}
+		if namePrefix != fmt.Sprintf("%s-a-%s-", s.Name, name) {
+			as.NamePrefix = namePrefix
+		}
+
+		for _, it := range items {
+			as.Attributes = append(as.Attributes, Attribute{Name: it.Name, Value: it.Value})
+		}
+
+		s.AttributeSets = append(s.AttributeSets, as)
+		return
+	}
+
+	if markerIndex(prefix, "_CMD_") != -1 {
+		if s.Operations.NamePrefix == "" {
+			s.Operations.NamePrefix = namePrefix
+		}
+
+		for _, it := range items {
+			s.Operations.List = append(s.Operations.List, Operation{
+				Name:       it.Name,
+				Value:      it.Value,
+				ReplyValue: it.Value,
+			})
+		}
+
+		return
+	}
+
+	name := kebabCase(tag)
+	if name == "" {
+		name = strings.TrimSuffix(namePrefix, "-")
+	}
+
+	d := Definition{
+		Name:       name,
+		Type:       DefinitionEnum,
+		NamePrefix: namePrefix,
+	}
+
+	for _, it := range items {
+		d.Entries = append(d.Entries, EnumEntry{Name: it.Name, Value: it.Value})
+	}
+
+	s.Definitions = append(s.Definitions, d)
+}
+
+// markerIndex returns the index following the first marker found in prefix,
+// or -1 if prefix contains none of them.
+func markerIndex(prefix string, markers ...string) int {
+	for _, m := range markers {
+		if i := strings.Index(prefix, m); i != -1 {
+			return i + len(m)
+		}
+	}
+
+	return -1
+}
+
+// commonPrefix returns the longest prefix ending in an underscore which is
+// shared by the names of each entry, ignoring leading underscores.
+func commonPrefix(entries []cEnumEntry) string {
+	prefix := strings.TrimLeft(entries[0].Name, "_")
+	for _, e := range entries[1:] {
+		name := strings.TrimLeft(e.Name, "_")
+
+		n := 0
+		for n < len(prefix) && n < len(name) && prefix[n] == name[n] {
+			n++
+		}
+
+		prefix = prefix[:n]
+	}
+
+	if len(entries) == 1 {
+		// The entire name is common, but is not a prefix.
+		prefix = strings.TrimSuffix(prefix, "_")
+	}
+
+	return prefix[:strings.LastIndex(prefix, "_")+1]
+}
+
+// kebabCase transforms a C identifier like "ETHTOOL_A_HEADER_" to
+// "ethtool-a-header-".
+func kebabCase(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", "-"))
+}
+
+// cInteger matches a C integer literal with a type suffix.
+var cInteger = regexp.MustCompile(`\b(0[xX][0-9a-fA-F]+|[0-9]+)[uUlL]+\b`)
+
+// cExpr converts a C constant expression to the form accepted by Spec.Eval,
+// in which integers have no type suffixes.
+func cExpr(expr string) string {
+	return strings.TrimSpace(cInteger.ReplaceAllString(expr, "$1"))
+}
+
+// stripComments removes C comments from src, replacing them with a space.
+func stripComments(src string) string {
+	var b strings.Builder
+	for len(src) > 0 {
+		switch {
+		case strings.HasPrefix(src, "/*"):
+			end := strings.Index(src[2:], "*/")
+			if end == -1 {
+				return b.String()
+			}
+
+			b.WriteByte(' ')
+			src = src[2+end+2:]
+		case strings.HasPrefix(src, "//"):
+			end := strings.IndexByte(src, '\n')
+			if end == -1 {
+				return b.String()
+			}
+
+			src = src[end:]
+		case src[0] == '"':
+			// Comment markers within strings are not comments.
+			end := strings.IndexByte(src[1:], '"')
+			if end == -1 {
+				end = len(src) - 2
+			}
+
+			b.WriteString(src[:end+2])
+			src = src[end+2:]
+		default:
+			b.WriteByte(src[0])
+			src = src[1:]
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,217 @@
+package yamlnetlink_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mdlayher/yamlnetlink"
+)
+
+func TestParseHeader(t *testing.T) {
+	f, err := os.Open("testdata/headers/foo.h")
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	got, err := yamlnetlink.ParseHeader(f, &yamlnetlink.HeaderOptions{UAPIHeader: "linux/foo.h"})
+	if err != nil {
+		t.Fatalf("failed to parse header: %v", err)
+	}
+
+	want := &yamlnetlink.Spec{
+		Name:       "foo",
+		UAPIHeader: "linux/foo.h",
+		Definitions: []yamlnetlink.Definition{{
+			Name:       "foo-state",
+			Type:       yamlnetlink.DefinitionEnum,
+			NamePrefix: "foo-state-",
+			Entries: []yamlnetlink.EnumEntry{
+				{Name: "down", Value: 0},
+				{Name: "up", Value: 4},
+			},
+		}},
+		AttributeSets: []yamlnetlink.AttributeSet{
+			{
+				Name: "header",
+				Attributes: []yamlnetlink.Attribute{
+					{Name: "dev-index", Value: 1},
+					{Name: "dev-name", Value: 2},
+					{Name: "flags", Value: 3},
+				},
+			},
+			{
+				Name: "channels",
+				Attributes: []yamlnetlink.Attribute{
+					{Name: "header", Value: 1},
+					{Name: "rx-max", Value: 5},
+					{Name: "tx-max", Value: 6},
+				},
+			},
+		},
+		Operations: yamlnetlink.Operations{
+			NamePrefix: "foo-cmd-",
+			List: []yamlnetlink.Operation{
+				{Name: "channels-get", Value: 1, ReplyValue: 1},
+				{Name: "channels-set", Value: 2, ReplyValue: 2},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(yamlnetlink.Spec{})); diff != "" {
+		t.Fatalf("unexpected Spec (-want +got):\n%s", diff)
+	}
+
+	// The skeleton must survive a round trip through YAML unchanged.
+	b, err := yamlnetlink.Marshal(got)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	roundTrip(t, string(b))
+}
+
+func TestParseHeaderNamePrefixes(t *testing.T) {
+	// An excerpt of linux/genetlink.h, whose prefixes do not follow the
+	// defaults.
+	const in = `
+enum {
+	CTRL_CMD_UNSPEC,
+	CTRL_CMD_NEWFAMILY,
+	CTRL_CMD_DELFAMILY,
+	CTRL_CMD_GETFAMILY,
+	__CTRL_CMD_MAX,
+};
+
+enum {
+	CTRL_ATTR_UNSPEC,
+	CTRL_ATTR_FAMILY_ID,
+	CTRL_ATTR_FAMILY_NAME,
+	__CTRL_ATTR_MAX,
+};
+
+enum {
+	CTRL_ATTR_OP_UNSPEC,
+	CTRL_ATTR_OP_ID,
+	CTRL_ATTR_OP_FLAGS,
+	__CTRL_ATTR_OP_MAX,
+};
+`
+
+	got, err := yamlnetlink.ParseHeader(strings.NewReader(in), &yamlnetlink.HeaderOptions{Name: "nlctrl"})
+	if err != nil {
+		t.Fatalf("failed to parse header: %v", err)
+	}
+
+	type set struct{ Name, NamePrefix string }
+	var sets []set
+	for _, as := range got.AttributeSets {
+		sets = append(sets, set{as.Name, as.NamePrefix})
+	}
+
+	want := []set{
+		{Name: "main", NamePrefix: "ctrl-attr-"},
+		{Name: "op", NamePrefix: "ctrl-attr-op-"},
+	}
+
+	if diff := cmp.Diff(want, sets); diff != "" {
+		t.Fatalf("unexpected attribute sets (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("ctrl-cmd-", got.Operations.NamePrefix); diff != "" {
+		t.Fatalf("unexpected operations name prefix (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseHeaderUnevaluated(t *testing.T) {
+	// Macros are not expanded, so enums which use them are skipped and
+	// reported without affecting the rest of the header.
+	const in = `
+#define FOO_GENL_NAME "foo"
+
+enum foo_flags {
+	FOO_FLAG_UP = BIT(0),
+	FOO_FLAG_DOWN = BIT(1),
+};
+
+enum {
+	FOO_A_UNSPEC,
+	FOO_A_FLAGS,
+	FOO_A_SIZE = sizeof(struct foo),
+};
+
+enum foo_state {
+	FOO_STATE_DOWN,
+	FOO_STATE_UP,
+};
+`
+
+	got, err := yamlnetlink.ParseHeader(strings.NewReader(in), nil)
+
+	var errs yamlnetlink.HeaderEnumErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected HeaderEnumErrors, but got: %v", err)
+	}
+
+	wantErrs := yamlnetlink.HeaderEnumErrors{
+		{
+			Enum:  "foo_flags",
+			Entry: "FOO_FLAG_UP",
+			Err: &yamlnetlink.ExprError{
+				Expr:    "BIT(0)",
+				Message: `unexpected "("`,
+			},
+		},
+		{
+			Entry: "FOO_A_SIZE",
+			Err: &yamlnetlink.ExprError{
+				Expr:    "sizeof(struct foo)",
+				Message: `unexpected "("`,
+			},
+		},
+	}
+
+	if diff := cmp.Diff(wantErrs, errs); diff != "" {
+		t.Fatalf("unexpected HeaderEnumErrors (-want +got):\n%s", diff)
+	}
+
+	want := []yamlnetlink.Definition{{
+		Name:       "foo-state",
+		Type:       yamlnetlink.DefinitionEnum,
+		NamePrefix: "foo-state-",
+		Entries: []yamlnetlink.EnumEntry{
+			{Name: "down", Value: 0},
+			{Name: "up", Value: 1},
+		},
+	}}
+
+	if diff := cmp.Diff(want, got.Definitions); diff != "" {
+		t.Fatalf("unexpected definitions (-want +got):\n%s", diff)
+	}
+	if len(got.AttributeSets) != 0 {
+		t.Fatalf("expected no attribute sets, but got: %v", got.AttributeSets)
+	}
+}
+
+func TestParseHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{
+			name: "no family name",
+			in:   "enum { FOO_A_UNSPEC, FOO_A_BAR };",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := yamlnetlink.ParseHeader(strings.NewReader(tt.in), nil); err == nil {
+				t.Fatal("expected an error, but none occurred")
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+// Package introspect reconstructs draft YAML netlink specifications from the
+// attribute policies which the running kernel reports for generic netlink
+// families via nlctrl's getpolicy operation.
+//
+// A policy describes the attributes a family accepts, their types, bounds,
+// and nesting, and which policy each operation uses. It does not describe
+// names, replies, or multicast groups, so a draft Spec is a starting point for
+// writing a specification rather than a replacement for one.
+package introspect
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/yamlnetlink"
+	"golang.org/x/exp/slices"
+)
+
+// Constants from linux/genetlink.h and linux/netlink.h. They are defined here
+// so that recorded policies can be parsed on any platform.
+const (
+	ctrlCmdGetPolicy = 10
+
+	ctrlAttrFamilyName = 2
+	ctrlAttrPolicy     = 8
+	ctrlAttrOpPolicy   = 9
+
+	ctrlAttrPolicyDo   = 1
+	ctrlAttrPolicyDump = 2
+
+	nlPolicyTypeAttrType        = 1
+	nlPolicyTypeAttrMinValueS   = 2
+	nlPolicyTypeAttrMaxValueS   = 3
+	nlPolicyTypeAttrMinValueU   = 4
+	nlPolicyTypeAttrMaxValueU   = 5
+	nlPolicyTypeAttrMinLength   = 6
+	nlPolicyTypeAttrMaxLength   = 7
+	nlPolicyTypeAttrPolicyIdx   = 8
+	nlPolicyTypeAttrBitfieldMsk = 10
+	nlPolicyTypeAttrMask        = 12
+)
+
+// types maps the kernel's NL_ATTR_TYPE_* values to YAML netlink attribute
+// types.
+var types = map[uint32]string{
+	1:  "flag",
+	2:  "u8",
+	3:  "u16",
+	4:  "u32",
+	5:  "u64",
+	6:  "s8",
+	7:  "s16",
+	8:  "s32",
+	9:  "s64",
+	10: "binary",
+	11: "string",     // NLA_STRING, which need not be NUL terminated.
+	12: "nul-string", // NLA_NUL_STRING.
+	13: "nest",
+	14: "indexed-array",
+	15: "bitfield32",
+	16: "sint",
+	17: "uint",
+}
+
+// Policy dumps the attribute policy of the generic netlink family name using
+// c, and reconstructs a draft Spec from it.
+func Policy(c *genetlink.Conn, name string) (*yamlnetlink.Spec, error) {
+	f, err := c.GetFamily("nlctrl")
+	if err != nil {
+		return nil, err
+	}
+
+	ae := netlink.NewAttributeEncoder()
+	ae.String(ctrlAttrFamilyName, name)
+	b, err := ae.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := c.Execute(
+		genetlink.Message{
+			Header: genetlink.Header{
+				Command: ctrlCmdGetPolicy,
+				Version: f.Version,
+			},
+			Data: b,
+		},
+		f.ID,
+		netlink.Request|netlink.Dump,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePolicy(name, msgs)
+}
+
+// ParsePolicy reconstructs a draft Spec for the generic netlink family name
+// from the messages of an nlctrl getpolicy dump, such as messages which were
+// previously recorded.
+//
+// Because a policy does not name anything, each attribute set is named after
+// its policy index, as in "policy-0", each attribute after its type, as in
+// "attr-1", and each operation after its command, as in "cmd-1".
+//
+// Some kernels report the policies of operations which only support dump
+// under command 0, which is never a valid command. Such operations are
+// omitted, but their policies are still included as attribute sets.
+func ParsePolicy(name string, msgs []genetlink.Message) (*yamlnetlink.Spec, error) {
+	p := &policy{sets: make(map[uint32]map[uint16]*yamlnetlink.Attribute)}
+	for i, m := range msgs {
+		if err := p.parse(m.Data); err != nil {
+			return nil, fmt.Errorf("introspect: failed to parse policy message %d: %w", i, err)
+		}
+	}
+
+	return p.spec(name), nil
+}
+
+// A policy is a parsed getpolicy dump.
+type policy struct {
+	// sets maps each policy index to its attributes by type.
+	sets map[uint32]map[uint16]*yamlnetlink.Attribute
+
+	// ops maps each command to its do and dump policy indexes, if any.
+	ops map[uint8]*opPolicy
+}
+
+// An opPolicy contains the policy indexes of an operation.
+type opPolicy struct {
+	do, dump       uint32
+	hasDo, hasDump bool
+}
+
+// parse parses the attributes of a single getpolicy message.
+func (p *policy) parse(b []byte) error {
+	ad, err := netlink.NewAttributeDecoder(b)
+	if err != nil {
+		return err
+	}
+
+	for ad.Next() {
+		switch ad.Type() {
+		case ctrlAttrPolicy:
+			// policy index -> attribute type -> policy attributes.
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				for nad.Next() {
+					idx := uint32(nad.Type())
+					nad.Nested(func(nad *netlink.AttributeDecoder) error {
+						for nad.Next() {
+							typ := nad.Type()
+							nad.Nested(func(nad *netlink.AttributeDecoder) error {
+								p.attribute(idx, typ, nad)
+								return nil
+							})
+						}
+
+						return nil
+					})
+				}
+
+				return nil
+			})
+		case ctrlAttrOpPolicy:
+			// command -> do and dump policy indexes.
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				for nad.Next() {
+					op := p.op(uint8(nad.Type()))
+					nad.Nested(func(nad *netlink.AttributeDecoder) error {
+						for nad.Next() {
+							switch nad.Type() {
+							case ctrlAttrPolicyDo:
+								op.do, op.hasDo = nad.Uint32(), true
+							case ctrlAttrPolicyDump:
+								op.dump, op.hasDump = nad.Uint32(), true
+							}
+						}
+
+						return nil
+					})
+				}
+
+				return nil
+			})
+		}
+	}
+
+	return ad.Err()
+}
+
+// op returns the opPolicy for cmd, creating it if necessary.
+func (p *policy) op(cmd uint8) *opPolicy {
+	if p.ops == nil {
+		p.ops = make(map[uint8]*opPolicy)
+	}
+
+	op, ok := p.ops[cmd]
+	if !ok {
+		op = &opPolicy{}
+		p.ops[cmd] = op
+	}
+
+	return op
+}
+
+// attribute parses the policy of attribute typ in policy idx.
+func (p *policy) attribute(idx uint32, typ uint16, ad *netlink.AttributeDecoder) {
+	set, ok := p.sets[idx]
+	if !ok {
+		set = make(map[uint16]*yamlnetlink.Attribute)
+		p.sets[idx] = set
+	}
+
+	a := &yamlnetlink.Attribute{
+		Name:  "attr-" + strconv.Itoa(int(typ)),
+		Value: int(typ),
+	}
+	set[typ] = a
+
+	var (
+		kind                   uint32
+		minS, maxS             int64
+		minU, maxU             uint64
+		hasMinS, hasMaxS       bool
+		hasMinU, hasMaxU       bool
+		minLen, maxLen         uint32
+		hasMinLen, hasMaxLen   bool
+		hasPolicy, hasBitfield bool
+	)
+
+	for ad.Next() {
+		switch ad.Type() {
+		case nlPolicyTypeAttrType:
+			kind = ad.Uint32()
+		case nlPolicyTypeAttrMinValueS:
+			minS, hasMinS = ad.Int64(), true
+		case nlPolicyTypeAttrMaxValueS:
+			maxS, hasMaxS = ad.Int64(), true
+		case nlPolicyTypeAttrMinValueU:
+			minU, hasMinU = ad.Uint64(), true
+		case nlPolicyTypeAttrMaxValueU:
+			maxU, hasMaxU = ad.Uint64(), true
+		case nlPolicyTypeAttrMinLength:
+			minLen, hasMinLen = ad.Uint32(), true
+		case nlPolicyTypeAttrMaxLength:
+			maxLen, hasMaxLen = ad.Uint32(), true
+		case nlPolicyTypeAttrPolicyIdx:
+			a.NestedAttributes = setName(ad.Uint32())
+			hasPolicy = true
+		case nlPolicyTypeAttrBitfieldMsk, nlPolicyTypeAttrMask:
+			hasBitfield = true
+		}
+	}
+
+	a.Type = types[kind]
+	switch {
+	case a.Type == "":
+		a.Type = "unused"
+		a.Doc = yamlnetlink.Doc(fmt.Sprintf("Unknown policy type %d.", kind))
+	case kind == 11:
+		a.Checks.UnterminatedOK = true
+	case a.Type == "indexed-array" && hasPolicy:
+		a.SubType = "nest"
+	}
+	if hasBitfield {
+		a.Doc = "Accepts only the bits of a mask which is reported by the kernel."
+	}
+
+	// The kernel reports the full range of integer types which have no
+	// bounds, so only report narrower bounds.
+	lo, hi := bounds(a.Type)
+	if hasMinS && minS > lo {
+		a.Checks.Min = strconv.FormatInt(minS, 10)
+	}
+	if hasMaxS && maxS < hi {
+		a.Checks.Max = strconv.FormatInt(maxS, 10)
+	}
+	if hasMinU && minU > 0 {
+		a.Checks.Min = strconv.FormatUint(minU, 10)
+	}
+	if hasMaxU && maxU < uint64(hi) {
+		a.Checks.Max = strconv.FormatUint(maxU, 10)
+	}
+
+	switch {
+	case hasMinLen && hasMaxLen && minLen == maxLen:
+		a.Checks.ExactLen = strconv.Itoa(int(minLen))
+	default:
+		if hasMinLen {
+			a.Checks.MinLen = strconv.Itoa(int(minLen))
+		}
+		if hasMaxLen {
+			a.Checks.MaxLen = strconv.Itoa(int(maxLen))
+		}
+	}
+}
+
+// bounds returns the range of values of an integer attribute type. Unsigned
+// 64-bit types are clamped to the range of int64.
+func bounds(typ string) (int64, int64) {
+	switch typ {
+	case "u8":
+		return 0, math.MaxUint8
+	case "u16":
+		return 0, math.MaxUint16
+	case "u32":
+		return 0, math.MaxUint32
+	case "s8":
+		return math.MinInt8, math.MaxInt8
+	case "s16":
+		return math.MinInt16, math.MaxInt16
+	case "s32":
+		return math.MinInt32, math.MaxInt32
+	default:
+		return math.MinInt64, math.MaxInt64
+	}
+}
+
+// setName returns the name of the attribute set for policy idx.
+func setName(idx uint32) string { return "policy-" + strconv.Itoa(int(idx)) }
+
+// spec builds a draft Spec from the parsed policy.
+func (p *policy) spec(name string) *yamlnetlink.Spec {
+	s := &yamlnetlink.Spec{
+		Name:     name,
+		Protocol: "genetlink",
+		Doc:      yamlnetlink.Doc(fmt.Sprintf("Draft specification for %s, reconstructed from the kernel's attribute policy.", name)),
+	}
+
+	// Attribute sets and attributes are ordered by their kernel values.
+	idxs := make([]uint32, 0, len(p.sets))
+	for idx := range p.sets {
+		idxs = append(idxs, idx)
+	}
+	slices.Sort(idxs)
+
+	attrs := make(map[uint32][]string, len(idxs))
+	for _, idx := range idxs {
+		set := p.sets[idx]
+		typs := make([]uint16, 0, len(set))
+		for typ := range set {
+			typs = append(typs, typ)
+		}
+		slices.Sort(typs)
+
+		as := yamlnetlink.AttributeSet{Name: setName(idx)}
+		for _, typ := range typs {
+			as.Attributes = append(as.Attributes, *set[typ])
+			attrs[idx] = append(attrs[idx], set[typ].Name)
+		}
+
+		s.AttributeSets = append(s.AttributeSets, as)
+	}
+
+	cmds := make([]uint8, 0, len(p.ops))
+	for cmd := range p.ops {
+		cmds = append(cmds, cmd)
+	}
+	slices.Sort(cmds)
+
+	for _, cmd := range cmds {
+		if cmd == 0 {
+			continue
+		}
+
+		var (
+			pol = p.ops[cmd]
+			op  = yamlnetlink.Operation{
+				Name:       "cmd-" + strconv.Itoa(int(cmd)),
+				Value:      int(cmd),
+				ReplyValue: int(cmd),
+			}
+		)
+
+		// An operation has a single attribute set, so prefer the do policy
+		// and only list dump attributes which also appear in it.
+		var set uint32
+		switch {
+		case pol.hasDo:
+			set = pol.do
+		case pol.hasDump:
+			set = pol.dump
+		default:
+			s.Operations.List = append(s.Operations.List, op)
+			continue
+		}
+
+		op.AttributeSet = setName(set)
+		if pol.hasDo {
+			op.Do.Request.Attributes = attrs[pol.do]
+		}
+		if pol.hasDump {
+			for _, a := range attrs[pol.dump] {
+				if slices.Contains(attrs[set], a) {
+					op.Dump.Request.Attributes = append(op.Dump.Request.Attributes, a)
+				}
+			}
+		}
+
+		s.Operations.List = append(s.Operations.List, op)
+	}
+
+	return s
+}
@@ -0,0 +1,317 @@
+package introspect_test
+
+import (
+	"bufio"
+	"encoding/hex"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/yamlnetlink"
+	"github.com/mdlayher/yamlnetlink/introspect"
+)
+
+var record = flag.Bool("record", false, "record policy dumps from the running kernel into testdata")
+
+func TestParsePolicyNlctrl(t *testing.T) {
+	s := parsePolicy(t, "nlctrl")
+
+	if err := s.Validate(); err != nil {
+		t.Fatalf("failed to validate draft spec: %v", err)
+	}
+
+	// CTRL_CMD_GETFAMILY and its policy.
+	getfamily := operation(t, s, 3)
+	if diff := cmp.Diff([]string{"attr-1", "attr-2"}, getfamily.Do.Request.Attributes); diff != "" {
+		t.Fatalf("unexpected getfamily do attributes (-want +got):\n%s", diff)
+	}
+
+	// CTRL_CMD_GETPOLICY only supports dump, so the recorded kernel reports
+	// it as command 0 and it is omitted, but its policy remains.
+	for _, op := range s.Operations.List {
+		if op.Value == 0 {
+			t.Fatalf("unexpected operation with command 0: %q", op.Name)
+		}
+	}
+
+	var getpolicy yamlnetlink.AttributeSet
+	for _, as := range s.AttributeSets {
+		if as.Name != getfamily.AttributeSet {
+			getpolicy = as
+		}
+	}
+
+	want := []yamlnetlink.Attribute{
+		// CTRL_ATTR_FAMILY_ID, CTRL_ATTR_FAMILY_NAME, and CTRL_ATTR_OP.
+		{Name: "attr-1", Type: "u16", Value: 1},
+		{
+			Name:   "attr-2",
+			Type:   "nul-string",
+			Value:  2,
+			Checks: yamlnetlink.Checks{MaxLen: "15"},
+		},
+		{Name: "attr-10", Type: "u32", Value: 10},
+	}
+
+	if diff := cmp.Diff(want, getpolicy.Attributes); diff != "" {
+		t.Fatalf("unexpected getpolicy attributes (-want +got):\n%s", diff)
+	}
+
+	if _, err := yamlnetlink.Generate(s, nil); err != nil {
+		t.Fatalf("failed to generate code from draft spec: %v", err)
+	}
+
+	b, err := yamlnetlink.Marshal(s)
+	if err != nil {
+		t.Fatalf("failed to marshal draft spec: %v", err)
+	}
+
+	if _, err := yamlnetlink.Parse(strings.NewReader(string(b))); err != nil {
+		t.Fatalf("failed to parse marshaled draft spec: %v", err)
+	}
+}
+
+func TestParsePolicyNested(t *testing.T) {
+	// A synthetic policy with nesting and bounds which nlctrl does not use.
+	pol := func(idx uint16, attrs func(ae *netlink.AttributeEncoder)) genetlink.Message {
+		ae := netlink.NewAttributeEncoder()
+		ae.Nested(8, func(ae *netlink.AttributeEncoder) error {
+			ae.Nested(idx, func(ae *netlink.AttributeEncoder) error {
+				attrs(ae)
+				return nil
+			})
+			return nil
+		})
+
+		return genetlink.Message{Data: mustEncode(t, ae)}
+	}
+
+	attr := func(ae *netlink.AttributeEncoder, typ uint16, fn func(ae *netlink.AttributeEncoder)) {
+		ae.Nested(typ, func(ae *netlink.AttributeEncoder) error {
+			fn(ae)
+			return nil
+		})
+	}
+
+	ops := netlink.NewAttributeEncoder()
+	ops.Nested(9, func(ae *netlink.AttributeEncoder) error {
+		ae.Nested(1, func(ae *netlink.AttributeEncoder) error {
+			ae.Uint32(1, 0)
+			ae.Uint32(2, 0)
+			return nil
+		})
+		return nil
+	})
+
+	msgs := []genetlink.Message{
+		pol(0, func(ae *netlink.AttributeEncoder) {
+			attr(ae, 1, func(ae *netlink.AttributeEncoder) {
+				ae.Uint32(1, 13) // NL_ATTR_TYPE_NESTED
+				ae.Uint32(8, 1)
+				ae.Uint32(9, 2)
+			})
+			attr(ae, 2, func(ae *netlink.AttributeEncoder) {
+				ae.Uint32(1, 2) // NL_ATTR_TYPE_U8
+				ae.Uint64(4, 1)
+				ae.Uint64(5, 255)
+			})
+		}),
+		pol(1, func(ae *netlink.AttributeEncoder) {
+			attr(ae, 1, func(ae *netlink.AttributeEncoder) {
+				ae.Uint32(1, 8) // NL_ATTR_TYPE_S32
+				ae.Int64(2, -10)
+				ae.Int64(3, 10)
+			})
+			attr(ae, 2, func(ae *netlink.AttributeEncoder) {
+				ae.Uint32(1, 10) // NL_ATTR_TYPE_BINARY
+				ae.Uint32(6, 6)
+				ae.Uint32(7, 6)
+			})
+			attr(ae, 3, func(ae *netlink.AttributeEncoder) {
+				ae.Uint32(1, 11) // NL_ATTR_TYPE_STRING
+				ae.Uint32(7, 32)
+			})
+			attr(ae, 4, func(ae *netlink.AttributeEncoder) {
+				ae.Uint32(1, 12) // NL_ATTR_TYPE_NUL_STRING
+				ae.Uint32(7, 16)
+			})
+		}),
+		{Data: mustEncode(t, ops)},
+	}
+
+	got, err := introspect.ParsePolicy("test", msgs)
+	if err != nil {
+		t.Fatalf("failed to parse policy: %v", err)
+	}
+
+	want := []yamlnetlink.AttributeSet{
+		{
+			Name: "policy-0",
+			Attributes: []yamlnetlink.Attribute{
+				{Name: "attr-1", Type: "nest", Value: 1, NestedAttributes: "policy-1"},
+				{Name: "attr-2", Type: "u8", Value: 2, Checks: yamlnetlink.Checks{Min: "1"}},
+			},
+		},
+		{
+			Name: "policy-1",
+			Attributes: []yamlnetlink.Attribute{
+				{Name: "attr-1", Type: "s32", Value: 1, Checks: yamlnetlink.Checks{Min: "-10", Max: "10"}},
+				{Name: "attr-2", Type: "binary", Value: 2, Checks: yamlnetlink.Checks{ExactLen: "6"}},
+				{
+					Name:   "attr-3",
+					Type:   "string",
+					Value:  3,
+					Checks: yamlnetlink.Checks{MaxLen: "32", UnterminatedOK: true},
+				},
+				{
+					Name:   "attr-4",
+					Type:   "nul-string",
+					Value:  4,
+					Checks: yamlnetlink.Checks{MaxLen: "16"},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got.AttributeSets); diff != "" {
+		t.Fatalf("unexpected attribute sets (-want +got):\n%s", diff)
+	}
+
+	if err := got.Validate(); err != nil {
+		t.Fatalf("failed to validate draft spec: %v", err)
+	}
+}
+
+func TestPolicyLive(t *testing.T) {
+	c, err := genetlink.Dial(nil)
+	if err != nil {
+		t.Skipf("skipping, failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	s, err := introspect.Policy(c, "nlctrl")
+	if err != nil {
+		t.Fatalf("failed to dump policy: %v", err)
+	}
+
+	if err := s.Validate(); err != nil {
+		t.Fatalf("failed to validate draft spec: %v", err)
+	}
+}
+
+// parsePolicy parses the recorded policy dump for family, first recording it
+// from the running kernel if -record is set.
+func parsePolicy(t *testing.T, family string) *yamlnetlink.Spec {
+	t.Helper()
+
+	path := "testdata/" + family + ".txt"
+	if *record {
+		recordPolicy(t, family, path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open recorded policy: %v", err)
+	}
+	defer f.Close()
+
+	var msgs []genetlink.Message
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		b, err := hex.DecodeString(s.Text())
+		if err != nil {
+			t.Fatalf("failed to decode recorded message: %v", err)
+		}
+
+		var m genetlink.Message
+		if err := m.UnmarshalBinary(b); err != nil {
+			t.Fatalf("failed to unmarshal recorded message: %v", err)
+		}
+
+		msgs = append(msgs, m)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("failed to read recorded policy: %v", err)
+	}
+
+	spec, err := introspect.ParsePolicy(family, msgs)
+	if err != nil {
+		t.Fatalf("failed to parse policy: %v", err)
+	}
+
+	return spec
+}
+
+// recordPolicy dumps the policy of family from the running kernel into path,
+// one hex-encoded generic netlink message per line.
+func recordPolicy(t *testing.T, family, path string) {
+	t.Helper()
+
+	c, err := genetlink.Dial(nil)
+	if err != nil {
+		t.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	f, err := c.GetFamily("nlctrl")
+	if err != nil {
+		t.Fatalf("failed to get nlctrl: %v", err)
+	}
+
+	ae := netlink.NewAttributeEncoder()
+	ae.String(2, family) // CTRL_ATTR_FAMILY_NAME
+
+	msgs, err := c.Execute(
+		genetlink.Message{
+			Header: genetlink.Header{Command: 10, Version: f.Version}, // CTRL_CMD_GETPOLICY
+			Data:   mustEncode(t, ae),
+		},
+		f.ID,
+		netlink.Request|netlink.Dump,
+	)
+	if err != nil {
+		t.Fatalf("failed to dump policy: %v", err)
+	}
+
+	var sb strings.Builder
+	for _, m := range msgs {
+		b, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to marshal message: %v", err)
+		}
+
+		sb.WriteString(hex.EncodeToString(b) + "\n")
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		t.Fatalf("failed to write recorded policy: %v", err)
+	}
+}
+
+func operation(t *testing.T, s *yamlnetlink.Spec, cmd int) yamlnetlink.Operation {
+	t.Helper()
+
+	for _, op := range s.Operations.List {
+		if op.Value == cmd {
+			return op
+		}
+	}
+
+	t.Fatalf("operation %d not found", cmd)
+	panic("unreachable")
+}
+
+func mustEncode(t *testing.T, ae *netlink.AttributeEncoder) []byte {
+	t.Helper()
+
+	b, err := ae.Encode()
+	if err != nil {
+		t.Fatalf("failed to encode attributes: %v", err)
+	}
+
+	return b
+}
@@ -0,0 +1,96 @@
+package yamlnetlink
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+)
+
+// ParseFile parses the YAML netlink specification file at path in the same way
+// as ParseWithOptions. If opts is nil, a default ParseOptions is used.
+func ParseFile(path string, opts *ParseOptions) (*Spec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseWithOptions(f, opts)
+}
+
+// ParseFS parses the YAML netlink specification file name from fsys in the same
+// way as ParseWithOptions. If opts is nil, a default ParseOptions is used.
+func ParseFS(fsys fs.FS, name string, opts *ParseOptions) (*Spec, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseWithOptions(f, opts)
+}
+
+// A Registry is a collection of Specs which can be looked up by family name.
+// Use LoadRegistry to create a Registry.
+type Registry struct {
+	specs map[string]*Spec
+}
+
+// LoadRegistry parses every ".yaml" file in directory dir of fsys into a
+// Registry. Use os.DirFS to load a directory from the local filesystem. If opts
+// is nil, a default ParseOptions is used.
+//
+// Specs are indexed by the family name within the specification rather than
+// the file name. It is an error for two files to specify the same family.
+func LoadRegistry(fsys fs.FS, dir string, opts *ParseOptions) (*Registry, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		r     = &Registry{specs: make(map[string]*Spec, len(names))}
+		files = make(map[string]string, len(names))
+	)
+
+	for _, name := range names {
+		s, err := ParseFS(fsys, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("yamlnetlink: failed to parse %q: %w", name, err)
+		}
+
+		if prev, ok := files[s.Name]; ok {
+			return nil, fmt.Errorf("yamlnetlink: family %q is specified by both %q and %q", s.Name, prev, name)
+		}
+
+		files[s.Name] = name
+		r.specs[s.Name] = s
+	}
+
+	return r, nil
+}
+
+// Lookup returns a copy of the Spec for family name and reports whether it was
+// found. The caller may modify the returned Spec without affecting the
+// Registry.
+func (r *Registry) Lookup(name string) (*Spec, bool) {
+	s, ok := r.specs[name]
+	if !ok {
+		return nil, false
+	}
+
+	return s.copy(), true
+}
+
+// Names returns the sorted family names of each Spec in the Registry.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.specs))
+	for n := range r.specs {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+	return names
+}
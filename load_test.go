@@ -0,0 +1,106 @@
+package yamlnetlink_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mdlayher/yamlnetlink"
+)
+
+func TestParseFileFS(t *testing.T) {
+	want := parseFile(t, "testdata/nlctrl/nlctrl.yaml")
+
+	got, err := yamlnetlink.ParseFile("testdata/nlctrl/nlctrl.yaml", nil)
+	if err != nil {
+		t.Fatalf("failed to parse file: %v", err)
+	}
+
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(yamlnetlink.Spec{})); diff != "" {
+		t.Fatalf("unexpected ParseFile Spec (-want +got):\n%s", diff)
+	}
+
+	got, err = yamlnetlink.ParseFS(os.DirFS("testdata"), "nlctrl/nlctrl.yaml", nil)
+	if err != nil {
+		t.Fatalf("failed to parse FS: %v", err)
+	}
+
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(yamlnetlink.Spec{})); diff != "" {
+		t.Fatalf("unexpected ParseFS Spec (-want +got):\n%s", diff)
+	}
+
+	if _, err := yamlnetlink.ParseFile("testdata/nonexistent.yaml", nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected not exist error, but got: %v", err)
+	}
+}
+
+func TestLoadRegistry(t *testing.T) {
+	fsys := fstest.MapFS{
+		"specs/a.yaml":     {Data: []byte("name: foo\n")},
+		"specs/b.yaml":     {Data: []byte("name: bar\n")},
+		"specs/README.md":  {Data: []byte("not a spec")},
+		"specs/c/baz.yaml": {Data: []byte("name: baz\n")},
+	}
+
+	r, err := yamlnetlink.LoadRegistry(fsys, "specs", nil)
+	if err != nil {
+		t.Fatalf("failed to load registry: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"bar", "foo"}, r.Names()); diff != "" {
+		t.Fatalf("unexpected names (-want +got):\n%s", diff)
+	}
+
+	s, ok := r.Lookup("foo")
+	if !ok {
+		t.Fatal("family foo was not found")
+	}
+	if diff := cmp.Diff("foo", s.Name); diff != "" {
+		t.Fatalf("unexpected family name (-want +got):\n%s", diff)
+	}
+
+	if _, ok := r.Lookup("baz"); ok {
+		t.Fatal("family baz in subdirectory should not be found")
+	}
+}
+
+func TestLoadRegistryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		opts *yamlnetlink.ParseOptions
+	}{
+		{
+			name: "duplicate",
+			fsys: fstest.MapFS{
+				"a.yaml": {Data: []byte("name: foo\n")},
+				"b.yaml": {Data: []byte("name: foo\n")},
+			},
+		},
+		{
+			name: "invalid YAML",
+			fsys: fstest.MapFS{
+				"a.yaml": {Data: []byte("name: [\n")},
+			},
+		},
+		{
+			name: "strict",
+			fsys: fstest.MapFS{
+				"a.yaml": {Data: []byte("name: foo\nbad: true\n")},
+			},
+			opts: &yamlnetlink.ParseOptions{Strict: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := yamlnetlink.LoadRegistry(tt.fsys, ".", tt.opts); err == nil {
+				t.Fatal("expected an error, but none occurred")
+			}
+		})
+	}
+}
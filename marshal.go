@@ -0,0 +1,516 @@
+package yamlnetlink
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Marshal encodes a Spec as a YAML netlink specification in the canonical form
+// used by the upstream kernel specifications: keys appear in a fixed order,
+// sequences of mappings begin with a dash on its own line, and top-level keys
+// are separated by blank lines.
+//
+// Numeric values which Parse computes from the specification's numbering
+// rules are only written when they cannot be inferred. If s was produced by
+// Parse, its comments, anchors, aliases, and scalar styles are preserved
+// wherever the corresponding parts of the Spec are unchanged.
+func Marshal(s *Spec) ([]byte, error) {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(s.document(false)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return upstreamStyle(b.Bytes()), nil
+}
+
+// MarshalYAML implements yaml.Marshaler. Unlike Marshal, the output uses the
+// formatting of the yaml package.
+func (s *Spec) MarshalYAML() (any, error) {
+	return s.document(false).Content[0], nil
+}
+
+// document builds a YAML document node for s. If explicit is true, every
+// computed value is written even if it could be inferred.
+func (s *Spec) document(explicit bool) *yaml.Node {
+	m := &marshaler{
+		s:        s,
+		explicit: explicit,
+		orig:     make(map[*yaml.Node]*yaml.Node),
+	}
+
+	doc := &yaml.Node{
+		Kind:    yaml.DocumentNode,
+		Content: []*yaml.Node{m.node(reflect.ValueOf(*s), deref(s.node))},
+	}
+	if s.node != nil {
+		doc.HeadComment = s.node.HeadComment
+		doc.FootComment = s.node.FootComment
+	}
+
+	m.link(doc, make(map[*yaml.Node]*yaml.Node))
+	return doc
+}
+
+// specKeys is the order of the top-level keys in upstream specifications.
+var specKeys = []string{
+	"name", "protocol", "protonum", "doc", "uapi-header",
+	"definitions", "attribute-sets", "sub-messages", "operations", "mcast-groups",
+}
+
+// A marshaler builds YAML nodes from the types of a Spec.
+type marshaler struct {
+	s        *Spec
+	explicit bool
+
+	// orig maps each built node to the node at the same location in the
+	// parsed document, if any.
+	orig map[*yaml.Node]*yaml.Node
+}
+
+// node builds a YAML node for v. orig is the node at the same location in the
+// parsed document, or nil.
+func (m *marshaler) node(v reflect.Value, orig *yaml.Node) *yaml.Node {
+	var n *yaml.Node
+	switch v.Kind() {
+	case reflect.Struct:
+		n = m.mapping(v, deref(orig))
+	case reflect.Slice:
+		n = &yaml.Node{Kind: yaml.SequenceNode}
+
+		var content []*yaml.Node
+		if o := deref(orig); o != nil && o.Kind == yaml.SequenceNode {
+			content = o.Content
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			var o *yaml.Node
+			if i < len(content) {
+				o = content[i]
+			}
+
+			n.Content = append(n.Content, m.node(v.Index(i), o))
+		}
+	case reflect.String:
+		n = scalar(v.String(), deref(orig))
+	case reflect.Int:
+		n = m.integer(int(v.Int()), deref(orig))
+	case reflect.Bool:
+		n = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v.Bool())}
+	default:
+		panic(fmt.Sprintf("yamlnetlink: cannot marshal value of type %s", v.Type()))
+	}
+
+	if o := deref(orig); o != nil {
+		if o.Kind == n.Kind && n.Kind != yaml.ScalarNode {
+			// Keep flow style for short lists and mappings.
+			n.Style = o.Style & yaml.FlowStyle
+		}
+
+		n.HeadComment = o.HeadComment
+		n.LineComment = o.LineComment
+		n.FootComment = o.FootComment
+		m.orig[n] = orig
+	}
+
+	return n
+}
+
+// mapping builds a YAML mapping node for struct v.
+func (m *marshaler) mapping(v reflect.Value, orig *yaml.Node) *yaml.Node {
+	var (
+		t      = v.Type()
+		fields = yamlFields(t)
+		keys   = make([]string, 0, len(fields))
+	)
+
+	if t == reflect.TypeOf(Spec{}) {
+		keys = specKeys
+	} else {
+		for i := 0; i < t.NumField(); i++ {
+			key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if _, ok := fields[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range keys {
+		var (
+			f     = fields[key]
+			fv    = v.FieldByIndex(f.Index)
+			ok, o = keyOf(orig, key)
+		)
+
+		// Computed values are always built so that fixups can decide whether
+		// they must be written.
+		computed := key == "value" && computedValue[t]
+		if fv.IsZero() && o == nil && !computed {
+			continue
+		}
+
+		vn := m.node(fv, o)
+		if fv.Kind() == reflect.Struct && len(vn.Content) == 0 && o == nil {
+			// Omit empty mappings which were not in the original document.
+			continue
+		}
+
+		kn := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+		if ok != nil {
+			kn.HeadComment = ok.HeadComment
+			kn.LineComment = ok.LineComment
+			kn.FootComment = ok.FootComment
+		}
+
+		n.Content = append(n.Content, kn, vn)
+	}
+
+	switch x := v.Interface().(type) {
+	case Definition:
+		m.definition(x, n)
+	case AttributeSet:
+		m.attributeSet(x, n)
+	case Operations:
+		m.operations(x, n)
+	}
+
+	return n
+}
+
+// computedValue contains the types whose values Parse may compute from the
+// specification's numbering rules.
+var computedValue = map[reflect.Type]bool{
+	reflect.TypeOf(EnumEntry{}): true,
+	reflect.TypeOf(Attribute{}): true,
+	reflect.TypeOf(Operation{}): true,
+}
+
+// definition writes the values of a Definition's entries which cannot be
+// inferred, and collapses entries with only a name to a plain scalar.
+func (m *marshaler) definition(d Definition, n *yaml.Node) {
+	entries := valueOf(n, "entries")
+	if entries == nil {
+		return
+	}
+
+	next := d.ValueStart
+	for i, e := range d.Entries {
+		en := entries.Content[i]
+		m.setValue(en, e.Value, e.Value != next)
+		next = e.Value + 1
+
+		if len(en.Content) == 2 {
+			*en = *scalar(e.Name, deref(m.orig[en]))
+		}
+	}
+}
+
+// attributeSet writes the values of an AttributeSet's attributes which cannot
+// be inferred. The attributes of subsets only contain the fields which differ
+// from the parent set.
+func (m *marshaler) attributeSet(as AttributeSet, n *yaml.Node) {
+	attrs := valueOf(n, "attributes")
+	if attrs == nil {
+		return
+	}
+
+	if as.SubsetOf == "" {
+		next := 1
+		for i, a := range as.Attributes {
+			m.setValue(attrs.Content[i], a.Value, a.Value != next)
+			next = a.Value + 1
+		}
+
+		return
+	}
+
+	var parent AttributeSet
+	for _, ps := range m.s.AttributeSets {
+		if ps.Name == as.SubsetOf {
+			parent = ps
+		}
+	}
+
+	for i, a := range as.Attributes {
+		an := attrs.Content[i]
+		deleteKey(an, "value")
+
+		pa, ok := parent.attribute(a.Name)
+		if !ok {
+			continue
+		}
+
+		var (
+			av     = reflect.ValueOf(a)
+			pv     = reflect.ValueOf(pa)
+			fields = yamlFields(av.Type())
+			orig   = deref(m.orig[an])
+		)
+
+		for key, f := range fields {
+			if key == "name" || hasKey(orig, key) {
+				continue
+			}
+
+			if reflect.DeepEqual(av.FieldByIndex(f.Index).Interface(), pv.FieldByIndex(f.Index).Interface()) {
+				deleteKey(an, key)
+			}
+		}
+	}
+}
+
+// operations writes the values of Operations which cannot be inferred.
+func (m *marshaler) operations(ops Operations, n *yaml.Node) {
+	list := valueOf(n, "list")
+	if list == nil {
+		return
+	}
+
+	if ops.EnumModel != EnumModelDirectional {
+		next := 1
+		for i, op := range ops.List {
+			m.setValue(list.Content[i], op.Value, op.Value != next)
+			next = op.Value + 1
+		}
+
+		return
+	}
+
+	// Mirror the directional numbering rules of Operations.number.
+	req, rep := 1, 1
+	for i, op := range ops.List {
+		on := list.Content[i]
+
+		if op.Notify != "" || hasKey(on, "event") {
+			m.setValue(on, op.ReplyValue, op.ReplyValue != rep)
+			rep = op.ReplyValue + 1
+			continue
+		}
+
+		oas, mode := op.Do, "do"
+		if !hasKey(on, "do") {
+			oas, mode = op.Dump, "dump"
+		}
+
+		// Commands are numbered only by their request and reply values.
+		deleteKey(on, "value")
+
+		if m.explicit || op.Value != req || oas.Request.Value != 0 {
+			m.setKey(on, op.Value, mode, "request", "value")
+		}
+		req = op.Value + 1
+
+		if op.ReplyValue != 0 {
+			if m.explicit || op.ReplyValue != rep || oas.Reply.Value != 0 {
+				m.setKey(on, op.ReplyValue, mode, "reply", "value")
+			}
+
+			rep = op.ReplyValue + 1
+		}
+	}
+}
+
+// setValue sets the value key of mapping n to v if emit is true, all values
+// are explicit, or the parsed document contained an explicit value, and
+// removes it otherwise.
+func (m *marshaler) setValue(n *yaml.Node, v int, emit bool) {
+	if !emit && !m.explicit && !hasKey(deref(m.orig[n]), "value") {
+		deleteKey(n, "value")
+		return
+	}
+
+	m.setKey(n, v, "value")
+}
+
+// link walks the built document n in order, restoring the anchors of the
+// parsed document and replacing nodes with aliases where the aliased content
+// is unchanged. anchors maps anchored nodes of the parsed document to their
+// built nodes.
+func (m *marshaler) link(n *yaml.Node, anchors map[*yaml.Node]*yaml.Node) {
+	for i, c := range n.Content {
+		o := m.orig[c]
+		if o == nil {
+			m.link(c, anchors)
+			continue
+		}
+
+		if o.Kind == yaml.AliasNode {
+			if a := anchors[o.Alias]; a != nil && sameNode(a, c) {
+				n.Content[i] = &yaml.Node{Kind: yaml.AliasNode, Value: a.Anchor, Alias: a}
+				continue
+			}
+		}
+
+		if o = deref(o); o.Anchor != "" {
+			c.Anchor = o.Anchor
+			anchors[o] = c
+		}
+
+		m.link(c, anchors)
+	}
+}
+
+// integer builds an integer scalar node for v, reusing orig if it is a constant
+// expression which evaluates to v.
+func (m *marshaler) integer(v int, orig *yaml.Node) *yaml.Node {
+	if orig != nil && orig.Kind == yaml.ScalarNode && orig.Tag != "!!int" {
+		if x, err := m.s.Eval(orig.Value); err == nil && x == int64(v) {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: orig.Tag, Style: orig.Style, Value: orig.Value}
+		}
+	}
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(v)}
+}
+
+// scalar builds a string scalar node for s, reusing the style of orig if it
+// represents the same string.
+func scalar(s string, orig *yaml.Node) *yaml.Node {
+	if orig != nil && orig.Kind == yaml.ScalarNode {
+		if orig.Value == s {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: orig.Tag, Style: orig.Style, Value: orig.Value}
+		}
+	}
+
+	tag := "!!str"
+	if _, err := strconv.Atoi(s); err == nil {
+		// Numeric strings such as Checks are written as integers.
+		tag = "!!int"
+	}
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: s}
+}
+
+// keyOf returns the key and value nodes for key in mapping n, without resolving
+// aliases, or nil if n is not a mapping or does not contain key.
+func keyOf(n *yaml.Node, key string) (k, v *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+
+	return nil, nil
+}
+
+// setKey sets the integer value at the path of keys within mapping n, creating
+// mappings as needed.
+func (m *marshaler) setKey(n *yaml.Node, v int, keys ...string) {
+	for i, key := range keys {
+		_, c := keyOf(n, key)
+		if c == nil {
+			c = &yaml.Node{Kind: yaml.MappingNode}
+			n.Content = append(n.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, c)
+		}
+
+		if i == len(keys)-1 {
+			*c = *m.integer(v, deref(m.orig[c]))
+		}
+
+		n = c
+	}
+}
+
+// deleteKey removes key from mapping n.
+func deleteKey(n *yaml.Node, key string) {
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			n.Content = append(n.Content[:i], n.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// deref resolves n if it is an alias.
+func deref(n *yaml.Node) *yaml.Node {
+	if n != nil && n.Kind == yaml.AliasNode {
+		return n.Alias
+	}
+	if n != nil && n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		return n.Content[0]
+	}
+
+	return n
+}
+
+// sameNode reports whether a and b have the same content.
+func sameNode(a, b *yaml.Node) bool {
+	a, b = deref(a), deref(b)
+	if a.Kind != b.Kind || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+
+	for i := range a.Content {
+		if !sameNode(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+var (
+	// seqMapping matches a sequence item which begins a block mapping.
+	seqMapping = regexp.MustCompile(`^( *)- ([^ #'"{\[|>-][^:]*:( |$))`)
+
+	// blockScalar matches a line which begins a literal or folded scalar.
+	blockScalar = regexp.MustCompile(`^( *)(- )?[^ #][^:]*: [|>][-+0-9]*$`)
+)
+
+// upstreamStyle rewrites YAML produced by the yaml package in the style of the
+// upstream specifications.
+func upstreamStyle(b []byte) []byte {
+	var (
+		out   []string
+		lines = strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+
+		// The indentation of the key which began a block scalar, or -1.
+		block = -1
+	)
+
+	for i, l := range lines {
+		indent := len(l) - len(strings.TrimLeft(l, " "))
+		if block >= 0 {
+			if strings.TrimSpace(l) == "" || indent > block {
+				out = append(out, l)
+				continue
+			}
+
+			block = -1
+		}
+
+		if ms := blockScalar.FindStringSubmatch(l); ms != nil {
+			block = len(ms[1]) + len(ms[2])
+		}
+
+		if i > 0 && l != "" && indent == 0 && l[0] != '#' && out[len(out)-1] != "" {
+			// Separate top-level keys.
+			out = append(out, "")
+		}
+
+		if ms := seqMapping.FindStringSubmatch(l); ms != nil {
+			out = append(out, ms[1]+"-")
+			l = ms[1] + "  " + l[len(ms[1])+2:]
+		}
+
+		out = append(out, l)
+	}
+
+	return []byte(strings.Join(out, "\n") + "\n")
+}
@@ -0,0 +1,331 @@
+package yamlnetlink_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mdlayher/yamlnetlink"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	for _, family := range []string{"ethtool", "nlctrl"} {
+		t.Run(family, func(t *testing.T) {
+			b, err := os.ReadFile("testdata/" + family + "/" + family + ".yaml")
+			if err != nil {
+				t.Fatalf("failed to read file: %v", err)
+			}
+
+			roundTrip(t, string(b))
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	const in = `# SPDX-License-Identifier: MIT
+name: test
+doc: |
+  Test family.
+definitions:
+  - { name: mode, type: enum, entries: [ a, b, { name: c, value: 5 } ] }
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: foo, type: u32 }
+      - { name: bar, type: u32, value: 10 } # explicit
+      - { name: baz, type: u32, checks: { max: 100 } }
+  -
+    name: sub
+    subset-of: main
+    attributes:
+      - { name: bar }
+operations:
+  list:
+    -
+      name: get
+      attribute-set: main
+      do:
+        request: &req
+          attributes: [ foo ]
+        reply: *req
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	// Add an attribute whose value must be explicit, and a new operation
+	// whose value follows the previous one.
+	main := &s.AttributeSets[0]
+	main.Attributes = append(main.Attributes, yamlnetlink.Attribute{
+		Name:  "qux",
+		Type:  "string",
+		Value: 20,
+		Doc:   "A new attribute.",
+	})
+	s.Operations.List = append(s.Operations.List, yamlnetlink.Operation{
+		Name:         "set",
+		Value:        2,
+		AttributeSet: "main",
+	})
+
+	b, err := yamlnetlink.Marshal(s)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	const want = `# SPDX-License-Identifier: MIT
+
+name: test
+
+doc: |
+  Test family.
+
+definitions:
+  - {name: mode, type: enum, entries: [a, b, {name: c, value: 5}]}
+
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - {name: foo, type: u32}
+      - {name: bar, type: u32, value: 10} # explicit
+      - {name: baz, type: u32, checks: {max: 100}}
+      -
+        name: qux
+        type: string
+        value: 20
+        doc: A new attribute.
+  -
+    name: sub
+    subset-of: main
+    attributes:
+      - {name: bar}
+
+operations:
+  list:
+    -
+      name: get
+      attribute-set: main
+      do:
+        request: &req
+          attributes: [foo]
+        reply: *req
+    -
+      name: set
+      attribute-set: main
+`
+
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("unexpected YAML (-want +got):\n%s", diff)
+	}
+
+	roundTrip(t, string(b))
+}
+
+func TestMarshalValues(t *testing.T) {
+	const in = `
+name: test
+protocol: genetlink-legacy
+operations:
+  enum-model: directional
+  list:
+    -
+      name: strset-get
+      do:
+        request: { value: 1 }
+        reply: { value: 1 }
+    -
+      name: linkinfo-get
+      do:
+        request: {}
+        reply: {}
+    -
+      name: linkinfo-ntf
+      notify: linkinfo-get
+    -
+      name: channels-get
+      value: 17
+      dump:
+        reply: {}
+    -
+      name: channels-ntf
+      notify: channels-get
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	// Renumber operations so that explicit values are required in places
+	// where they were previously implicit.
+	ops := s.Operations.List
+	ops[1].Value, ops[1].ReplyValue = 5, 8
+	ops[2].ReplyValue = 9
+	ops[4].ReplyValue = 30
+
+	b, err := yamlnetlink.Marshal(s)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	got, err := yamlnetlink.Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to parse marshaled YAML: %v\n%s", err, b)
+	}
+
+	// Explicit values may be added to requests and replies, so only compare
+	// the resulting command IDs.
+	type value struct {
+		Name         string
+		Value, Reply int
+	}
+
+	values := func(s *yamlnetlink.Spec) []value {
+		var vs []value
+		for _, op := range s.Operations.List {
+			vs = append(vs, value{op.Name, op.Value, op.ReplyValue})
+		}
+
+		return vs
+	}
+
+	if diff := cmp.Diff(values(s), values(got)); diff != "" {
+		t.Fatalf("unexpected operation values (-want +got):\n%s", diff)
+	}
+}
+
+func TestMarshalExpressionValues(t *testing.T) {
+	const in = `
+name: test
+definitions:
+  - { name: base, type: const, value: 16 }
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: foo, type: u32, value: base + 1 }
+      - { name: bar, type: u32, value: base + 4 }
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	// Expressions are kept while they still evaluate to the attribute's value.
+	s.AttributeSets[0].Attributes[1].Value = 30
+
+	b, err := yamlnetlink.Marshal(s)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	const want = `      - {name: foo, type: u32, value: base + 1}
+      - {name: bar, type: u32, value: 30}
+`
+	if !strings.HasSuffix(string(b), want) {
+		t.Fatalf("unexpected attributes:\n%s", b)
+	}
+}
+
+func TestMarshalNoDocument(t *testing.T) {
+	s := &yamlnetlink.Spec{
+		Name: "test",
+		Doc:  "Test family.",
+		AttributeSets: []yamlnetlink.AttributeSet{{
+			Name: "main",
+			Attributes: []yamlnetlink.Attribute{
+				{Name: "foo", Type: "u32", Value: 1},
+				{Name: "bar", Type: "u32", Value: 3},
+			},
+		}},
+		Operations: yamlnetlink.Operations{
+			List: []yamlnetlink.Operation{{
+				Name:         "get",
+				Doc:          "Get things.",
+				Value:        1,
+				ReplyValue:   1,
+				AttributeSet: "main",
+			}},
+		},
+	}
+
+	b, err := yamlnetlink.Marshal(s)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	const want = `name: test
+
+doc: Test family.
+
+attribute-sets:
+  -
+    name: main
+    attributes:
+      -
+        name: foo
+        type: u32
+      -
+        name: bar
+        type: u32
+        value: 3
+
+operations:
+  list:
+    -
+      name: get
+      doc: Get things.
+      attribute-set: main
+`
+
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("unexpected YAML (-want +got):\n%s", diff)
+	}
+
+	// The marshaled Spec can now be validated against the schema.
+	if err := s.ValidateSchema(); err != nil {
+		t.Fatalf("failed to validate schema: %v", err)
+	}
+}
+
+// roundTrip verifies that the YAML in parses to the same Spec after it is
+// marshaled, and that marshaling is idempotent.
+func roundTrip(t *testing.T, in string) {
+	t.Helper()
+
+	want, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	b, err := yamlnetlink.Marshal(want)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	got, err := yamlnetlink.Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to parse marshaled YAML: %v\n%s", err, b)
+	}
+
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(yamlnetlink.Spec{})); diff != "" {
+		t.Fatalf("unexpected Spec (-want +got):\n%s", diff)
+	}
+
+	again, err := yamlnetlink.Marshal(got)
+	if err != nil {
+		t.Fatalf("failed to marshal again: %v", err)
+	}
+
+	if diff := cmp.Diff(string(b), string(again)); diff != "" {
+		t.Fatalf("marshaling is not idempotent (-want +got):\n%s", diff)
+	}
+}
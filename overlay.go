@@ -0,0 +1,293 @@
+package yamlnetlink
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// An Overlay is a set of local changes which can be applied to an upstream
+// Spec without modifying the upstream specification. Use ParseOverlay to
+// create an Overlay.
+//
+// An overlay file has the same structure as a YAML netlink specification, but
+// only contains the parts which should change. Mappings are merged key by key,
+// and the items of lists such as attribute-sets, attributes, and operations
+// are matched by name. Other values, such as the attributes of an operation's
+// request, replace the upstream values. An item with "remove: true" is removed
+// from its list. For example:
+//
+//	name: ethtool
+//	attribute-sets:
+//	  -
+//	    name: channels
+//	    attributes:
+//	      - { name: rx-max, doc: Maximum RX channels., byte-order: big-endian }
+//	operations:
+//	  list:
+//	    - { name: channels-set, remove: true }
+//
+// If the overlay specifies a name, it must match the name of the Spec.
+type Overlay struct {
+	node *yaml.Node
+}
+
+// ParseOverlay parses a YAML overlay file into an Overlay.
+func ParseOverlay(r io.Reader) (*Overlay, error) {
+	var n yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&n); err != nil {
+		return nil, err
+	}
+
+	if root := deref(&n); root == nil || root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("yamlnetlink: line %d: overlay must be a mapping", n.Line)
+	}
+
+	return &Overlay{node: &n}, nil
+}
+
+// Apply returns a copy of s with each Overlay applied in order. s is not
+// modified. Numeric values such as attribute and command IDs are not affected
+// by removing items, so they continue to match the upstream kernel.
+//
+// If an Overlay refers to an item which does not exist in s, Apply returns
+// ValidationErrors whose positions refer to the overlay file. The result has
+// no positions within the upstream specification.
+func (s *Spec) Apply(overlays ...*Overlay) (*Spec, error) {
+	// Make every value explicit so that removals cannot renumber the
+	// remaining items.
+	doc := copyNode(s.document(true))
+
+	var errs ValidationErrors
+	for _, o := range overlays {
+		o.apply(doc, &errs)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	out, err := parse(doc, &ParseOptions{Strict: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// The document no longer corresponds to any input.
+	out.node = nil
+	return out, nil
+}
+
+// apply merges the Overlay into the Spec document doc, reporting missing
+// targets in errs.
+func (o *Overlay) apply(doc *yaml.Node, errs *ValidationErrors) {
+	var (
+		dst = deref(doc)
+		src = deref(o.node)
+	)
+
+	if k, v := keyOf(src, "name"); k != nil {
+		if _, name := keyOf(dst, "name"); name == nil || name.Value != v.Value {
+			var want string
+			if name != nil {
+				want = name.Value
+			}
+
+			*errs = append(*errs, &ValidationError{
+				Path:    "name",
+				Line:    v.Line,
+				Column:  v.Column,
+				Message: fmt.Sprintf("overlay for family %q cannot be applied to family %q", v.Value, want),
+			})
+			return
+		}
+	}
+
+	merge(dst, src, "", errs)
+}
+
+// merge merges src into dst, returning the resulting node. path is the location
+// of src within the overlay.
+func merge(dst, src *yaml.Node, path string, errs *ValidationErrors) *yaml.Node {
+	src = deref(src)
+
+	switch {
+	case dst == nil:
+		return copyNode(src)
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		keys, values := mappingPairs(src)
+		for i, k := range keys {
+			kpath := k.Value
+			if path != "" {
+				kpath = path + "." + k.Value
+			}
+
+			found := false
+			for j := 0; j < len(dst.Content); j += 2 {
+				if dst.Content[j].Value == k.Value {
+					dst.Content[j+1] = merge(dst.Content[j+1], values[i], kpath, errs)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				dst.Content = append(dst.Content, copyNode(k), copyNode(values[i]))
+			}
+		}
+
+		return dst
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode && itemKey(src) != "":
+		mergeItems(dst, src, path, errs)
+		return dst
+	case dst.Kind == yaml.ScalarNode && src.Kind == yaml.MappingNode && hasKey(src, "name"):
+		// Enum entries may be written as plain names, but overlays may add
+		// fields to them.
+		m := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "name"},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: dst.Value},
+		}}
+
+		return merge(m, src, path, errs)
+	default:
+		return copyNode(src)
+	}
+}
+
+// mergeItems merges the items of sequence src into the items of sequence dst
+// with the same name.
+func mergeItems(dst, src *yaml.Node, path string, errs *ValidationErrors) {
+	key := itemKey(src)
+	for i, item := range src.Content {
+		var (
+			ipath = fmt.Sprintf("%s[%d]", path, i)
+			name  = valueOf(item, key).Value
+		)
+
+		j := -1
+		for k, d := range dst.Content {
+			if itemName(d, key) == name {
+				j = k
+				break
+			}
+		}
+
+		if j == -1 {
+			*errs = append(*errs, &ValidationError{
+				Path:    ipath,
+				Line:    item.Line,
+				Column:  item.Column,
+				Message: fmt.Sprintf("%s %q does not exist", itemNoun(path), name),
+			})
+			continue
+		}
+
+		if remove := valueOf(item, "remove"); remove != nil {
+			var ok bool
+			if err := remove.Decode(&ok); err != nil || !ok {
+				*errs = append(*errs, &ValidationError{
+					Path:    ipath + ".remove",
+					Line:    remove.Line,
+					Column:  remove.Column,
+					Message: `remove must be true`,
+				})
+				continue
+			}
+
+			dst.Content = append(dst.Content[:j], dst.Content[j+1:]...)
+			continue
+		}
+
+		dst.Content[j] = merge(dst.Content[j], item, ipath, errs)
+	}
+}
+
+// itemKey returns the key used to match the items of sequence n, or the empty
+// string if its items are not matched by key. Sub-message formats are matched
+// by value, and all other items by name.
+func itemKey(n *yaml.Node) string {
+	for _, key := range []string{"name", "value"} {
+		ok := len(n.Content) > 0
+		for _, c := range n.Content {
+			if v := valueOf(c, key); v == nil || v.Kind != yaml.ScalarNode {
+				ok = false
+			}
+		}
+
+		if ok {
+			return key
+		}
+	}
+
+	return ""
+}
+
+// itemName returns the name of sequence item n as matched by key. Plain
+// scalars are their own names.
+func itemName(n *yaml.Node, key string) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	if v := valueOf(n, key); v != nil {
+		return v.Value
+	}
+
+	return ""
+}
+
+// itemNoun returns a description of the items of the list at path.
+func itemNoun(path string) string {
+	// Strip list indices and use the final key, or its parent for the
+	// generic "list" key.
+	keys := strings.Split(path, ".")
+	for i := range keys {
+		keys[i], _, _ = strings.Cut(keys[i], "[")
+	}
+
+	key := keys[len(keys)-1]
+	if key == "list" && len(keys) > 1 {
+		key = keys[len(keys)-2]
+	}
+
+	switch key {
+	case "definitions":
+		return "definition"
+	case "entries":
+		return "entry"
+	case "members":
+		return "member"
+	case "attribute-sets":
+		return "attribute set"
+	case "attributes":
+		return "attribute"
+	case "operations":
+		return "operation"
+	case "mcast-groups":
+		return "multicast group"
+	case "sub-messages":
+		return "sub-message"
+	case "formats":
+		return "format"
+	default:
+		return "item"
+	}
+}
+
+// copyNode returns a deep copy of n with all aliases expanded.
+func copyNode(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.AliasNode {
+		return copyNode(n.Alias)
+	}
+
+	c := *n
+	c.Anchor = ""
+	c.Content = make([]*yaml.Node, 0, len(n.Content))
+	for _, cn := range n.Content {
+		c.Content = append(c.Content, copyNode(cn))
+	}
+
+	return &c
+}
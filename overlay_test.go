@@ -0,0 +1,276 @@
+package yamlnetlink_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/yamlnetlink"
+)
+
+func TestSpecApply(t *testing.T) {
+	s := parseFile(t, "testdata/ethtool/ethtool.yaml")
+
+	const docs = `
+name: ethtool
+doc: Ethernet device configuration, with local patches.
+attribute-sets:
+  -
+    name: header
+    attributes:
+      - { name: dev-index, display-hint: hex }
+  -
+    name: channels
+    attributes:
+      - { name: rx-max, remove: true }
+      - { name: tx-max, byte-order: big-endian, doc: Maximum TX channels. }
+`
+
+	const ops = `
+operations:
+  list:
+    - { name: channels-set, remove: true }
+    -
+      name: channels-get
+      do:
+        reply:
+          attributes: [ header, tx-max ]
+      dump:
+        reply:
+          attributes: [ header, tx-max, tx-count ]
+`
+
+	got, err := s.Apply(parseOverlay(t, docs), parseOverlay(t, ops))
+	if err != nil {
+		t.Fatalf("failed to apply overlays: %v", err)
+	}
+
+	if diff := cmp.Diff(yamlnetlink.Doc("Ethernet device configuration, with local patches."), got.Doc); diff != "" {
+		t.Fatalf("unexpected doc (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(yamlnetlink.Hex, got.AttributeSets[0].Attributes[0].DisplayHint); diff != "" {
+		t.Fatalf("unexpected display hint (-want +got):\n%s", diff)
+	}
+
+	// Removing rx-max must not renumber the following attributes.
+	channels := got.AttributeSets[1]
+	want := yamlnetlink.Attribute{
+		Name:      "tx-max",
+		Type:      "u32",
+		Value:     3,
+		Doc:       "Maximum TX channels.",
+		ByteOrder: yamlnetlink.BigEndian,
+	}
+
+	if diff := cmp.Diff(want, channels.Attributes[1]); diff != "" {
+		t.Fatalf("unexpected attribute (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(9, channels.MaxValue()); diff != "" {
+		t.Fatalf("unexpected max value (-want +got):\n%s", diff)
+	}
+
+	var names []string
+	for _, op := range got.Operations.List {
+		names = append(names, op.Name)
+	}
+
+	if diff := cmp.Diff([]string{"channels-get", "channels-ntf"}, names); diff != "" {
+		t.Fatalf("unexpected operations (-want +got):\n%s", diff)
+	}
+
+	// The do and dump replies were previously aliases, but can be replaced
+	// independently.
+	get := got.Operations.List[0]
+	if diff := cmp.Diff([]string{"header", "tx-max"}, get.Do.Reply.Attributes); diff != "" {
+		t.Fatalf("unexpected do reply attributes (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"header", "tx-max", "tx-count"}, get.Dump.Reply.Attributes); diff != "" {
+		t.Fatalf("unexpected dump reply attributes (-want +got):\n%s", diff)
+	}
+
+	// The original Spec is unmodified.
+	if diff := cmp.Diff(3, len(s.Operations.List)); diff != "" {
+		t.Fatalf("unexpected number of original operations (-want +got):\n%s", diff)
+	}
+
+	if err := got.Validate(); err != nil {
+		t.Fatalf("failed to validate overlaid spec: %v", err)
+	}
+}
+
+func TestSpecApplyEnumEntries(t *testing.T) {
+	s, err := yamlnetlink.Parse(strings.NewReader(`
+name: test
+definitions:
+  - { name: mode, type: enum, entries: [ a, b, c ] }
+`))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	got, err := s.Apply(parseOverlay(t, `
+definitions:
+  -
+    name: mode
+    entries:
+      - { name: b, doc: The second mode. }
+      - { name: a, remove: true }
+`))
+	if err != nil {
+		t.Fatalf("failed to apply overlay: %v", err)
+	}
+
+	want := []yamlnetlink.EnumEntry{
+		{Name: "b", Doc: "The second mode.", Value: 1},
+		{Name: "c", Value: 2},
+	}
+
+	if diff := cmp.Diff(want, got.Definitions[0].Entries); diff != "" {
+		t.Fatalf("unexpected entries (-want +got):\n%s", diff)
+	}
+}
+
+func TestSpecApplyErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want yamlnetlink.ValidationErrors
+	}{
+		{
+			name: "family",
+			in:   "name: nlctrl\n",
+			want: yamlnetlink.ValidationErrors{{
+				Path:    "name",
+				Line:    1,
+				Column:  7,
+				Message: `overlay for family "nlctrl" cannot be applied to family "ethtool"`,
+			}},
+		},
+		{
+			name: "missing",
+			in: `
+attribute-sets:
+  -
+    name: channels
+    attributes:
+      - { name: rx-max, doc: Still here. }
+      - { name: rx-maximum, doc: Renamed upstream. }
+  -
+    name: strset
+operations:
+  list:
+    - { name: channels-set, remove: yes please }
+    - { name: linkinfo-get, remove: true }
+mcast-groups:
+  list:
+    - { name: monitor, doc: Still here. }
+    - { name: events }
+`,
+			want: yamlnetlink.ValidationErrors{
+				{
+					Path:    "attribute-sets[0].attributes[1]",
+					Line:    7,
+					Column:  9,
+					Message: `attribute "rx-maximum" does not exist`,
+				},
+				{
+					Path:    "attribute-sets[1]",
+					Line:    9,
+					Column:  5,
+					Message: `attribute set "strset" does not exist`,
+				},
+				{
+					Path:    "operations.list[0].remove",
+					Line:    12,
+					Column:  37,
+					Message: "remove must be true",
+				},
+				{
+					Path:    "operations.list[1]",
+					Line:    13,
+					Column:  7,
+					Message: `operation "linkinfo-get" does not exist`,
+				},
+				{
+					Path:    "mcast-groups.list[1]",
+					Line:    17,
+					Column:  7,
+					Message: `multicast group "events" does not exist`,
+				},
+			},
+		},
+	}
+
+	s := parseFile(t, "testdata/ethtool/ethtool.yaml")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got yamlnetlink.ValidationErrors
+			if _, err := s.Apply(parseOverlay(t, tt.in)); !errors.As(err, &got) {
+				t.Fatalf("expected ValidationErrors, but got: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("unexpected ValidationErrors (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSpecApplyUnknownField(t *testing.T) {
+	s := parseFile(t, "testdata/ethtool/ethtool.yaml")
+
+	_, err := s.Apply(parseOverlay(t, `
+attribute-sets:
+  -
+    name: header
+    attributes:
+      - { name: flags, dispaly-hint: hex }
+`))
+
+	var ufe *yamlnetlink.UnknownFieldError
+	if !errors.As(err, &ufe) {
+		t.Fatalf("expected UnknownFieldError, but got: %v", err)
+	}
+
+	want := &yamlnetlink.UnknownFieldError{
+		Path:   "attribute-sets[0].attributes[2].dispaly-hint",
+		Field:  "dispaly-hint",
+		Line:   6,
+		Column: 24,
+	}
+
+	if diff := cmp.Diff(want, ufe); diff != "" {
+		t.Fatalf("unexpected UnknownFieldError (-want +got):\n%s", diff)
+	}
+}
+
+func parseFile(t *testing.T, file string) *yamlnetlink.Spec {
+	t.Helper()
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	s, err := yamlnetlink.Parse(f)
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	return s
+}
+
+func parseOverlay(t *testing.T, in string) *yamlnetlink.Overlay {
+	t.Helper()
+
+	o, err := yamlnetlink.ParseOverlay(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse overlay: %v", err)
+	}
+
+	return o
+}
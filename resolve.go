@@ -0,0 +1,406 @@
+package yamlnetlink
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// A Family is a resolved Spec: a graph in which the references between
+// operations, attribute sets, attributes, and definitions are replaced by
+// direct pointers. A Family is created by Spec.Resolve and is immutable.
+//
+// A Family holds its own copy of the Spec it was resolved from. Pointers to
+// Spec types returned by a Family and its nodes refer to that copy and must
+// not be modified.
+type Family struct {
+	s *Spec
+
+	sets   []*ResolvedAttributeSet
+	setIdx map[string]*ResolvedAttributeSet
+
+	ops   []*ResolvedOperation
+	opIdx map[string]*ResolvedOperation
+
+	defIdx   map[string]*Definition
+	mcgrpIdx map[string]*MulticastGroup
+	subIdx   map[string]*SubMessage
+}
+
+// Resolve validates a Spec and resolves all of its references into a Family.
+// If the Spec is not valid, Resolve returns the ValidationErrors reported by
+// Validate. If an attribute's length or bounds cannot be evaluated, Resolve
+// returns the *ExprError reported by Eval.
+func (s *Spec) Resolve() (*Family, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	f := &Family{
+		// Copy the Spec so later changes to s cannot affect the Family.
+		s:        s.copy(),
+		setIdx:   make(map[string]*ResolvedAttributeSet),
+		opIdx:    make(map[string]*ResolvedOperation),
+		defIdx:   make(map[string]*Definition),
+		mcgrpIdx: make(map[string]*MulticastGroup),
+		subIdx:   make(map[string]*SubMessage),
+	}
+
+	for i := range f.s.Definitions {
+		d := &f.s.Definitions[i]
+		f.defIdx[d.Name] = d
+	}
+	for i := range f.s.MulticastGroups.List {
+		g := &f.s.MulticastGroups.List[i]
+		f.mcgrpIdx[g.Name] = g
+	}
+	for i := range f.s.SubMessages {
+		sm := &f.s.SubMessages[i]
+		f.subIdx[sm.Name] = sm
+	}
+
+	// Create every node before linking them, since references may point
+	// forward or back.
+	for i := range f.s.AttributeSets {
+		as := &f.s.AttributeSets[i]
+		rs := &ResolvedAttributeSet{
+			as:      as,
+			family:  f,
+			attrs:   make([]*ResolvedAttribute, 0, len(as.Attributes)),
+			nameIdx: make(map[string]*ResolvedAttribute, len(as.Attributes)),
+			idIdx:   make(map[int]*ResolvedAttribute, len(as.Attributes)),
+		}
+
+		for j := range as.Attributes {
+			ra := &ResolvedAttribute{a: &as.Attributes[j], set: rs}
+			rs.attrs = append(rs.attrs, ra)
+			rs.nameIdx[ra.a.Name] = ra
+			rs.idIdx[ra.a.Value] = ra
+		}
+
+		f.sets = append(f.sets, rs)
+		f.setIdx[as.Name] = rs
+	}
+
+	for i := range f.s.Operations.List {
+		op := &f.s.Operations.List[i]
+		ro := &ResolvedOperation{op: op}
+
+		f.ops = append(f.ops, ro)
+		f.opIdx[op.Name] = ro
+	}
+
+	for _, rs := range f.sets {
+		rs.parent = f.setIdx[rs.as.SubsetOf]
+
+		for _, ra := range rs.attrs {
+			a := ra.a
+			ra.nested = f.setIdx[a.NestedAttributes]
+			ra.enum = f.defIdx[a.Enum]
+			ra.strct = f.defIdx[a.Struct]
+			ra.sub = f.subIdx[a.SubMessage]
+			ra.selector = rs.nameIdx[a.Selector]
+			limits, err := f.s.limits(a)
+			if err != nil {
+				return nil, err
+			}
+			ra.limits = limits
+		}
+	}
+
+	for _, ro := range f.ops {
+		op := ro.op
+		ro.set = f.setIdx[op.AttributeSet]
+		ro.notify = f.opIdx[op.Notify]
+		ro.mcgrp = f.mcgrpIdx[op.MulticastGroup]
+
+		hdr := op.FixedHeader
+		if hdr == "" {
+			hdr = f.s.Operations.FixedHeader
+		}
+		ro.hdr = f.defIdx[hdr]
+	}
+
+	return f, nil
+}
+
+// Spec returns the Family's copy of the Spec it was resolved from.
+func (f *Family) Spec() *Spec { return f.s }
+
+// AttributeSets returns all of the Family's attribute sets in specification
+// order.
+func (f *Family) AttributeSets() []*ResolvedAttributeSet { return f.sets }
+
+// AttributeSet returns the named attribute set, or nil if it does not exist.
+func (f *Family) AttributeSet(name string) *ResolvedAttributeSet { return f.setIdx[name] }
+
+// AttributeByID returns the attribute with numeric ID id in the named
+// attribute set, or nil if either does not exist.
+func (f *Family) AttributeByID(set string, id int) *ResolvedAttribute {
+	rs := f.AttributeSet(set)
+	if rs == nil {
+		return nil
+	}
+
+	return rs.AttributeByID(id)
+}
+
+// Operations returns all of the Family's operations in specification order.
+func (f *Family) Operations() []*ResolvedOperation { return f.ops }
+
+// Operation returns the named operation, or nil if it does not exist.
+func (f *Family) Operation(name string) *ResolvedOperation { return f.opIdx[name] }
+
+// Definition returns the named Definition, or nil if it does not exist.
+func (f *Family) Definition(name string) *Definition { return f.defIdx[name] }
+
+// MulticastGroup returns the named MulticastGroup, or nil if it does not
+// exist.
+func (f *Family) MulticastGroup(name string) *MulticastGroup { return f.mcgrpIdx[name] }
+
+// SubMessage returns the named SubMessage, or nil if it does not exist.
+func (f *Family) SubMessage(name string) *SubMessage { return f.subIdx[name] }
+
+// A ResolvedAttributeSet is an AttributeSet within a Family.
+type ResolvedAttributeSet struct {
+	as     *AttributeSet
+	family *Family
+	parent *ResolvedAttributeSet
+
+	attrs   []*ResolvedAttribute
+	nameIdx map[string]*ResolvedAttribute
+	idIdx   map[int]*ResolvedAttribute
+}
+
+// Name returns the name of the attribute set.
+func (rs *ResolvedAttributeSet) Name() string { return rs.as.Name }
+
+// AttributeSet returns the underlying AttributeSet.
+func (rs *ResolvedAttributeSet) AttributeSet() *AttributeSet { return rs.as }
+
+// Parent returns the attribute set named by subset-of, or nil if this set is
+// not a subset.
+func (rs *ResolvedAttributeSet) Parent() *ResolvedAttributeSet { return rs.parent }
+
+// NamePrefix returns the prefix of the C constants for the set's attributes.
+// Subsets share the constants of their parent set, and sets without an
+// explicit name-prefix use the default of "<family>-a-<set>-".
+func (rs *ResolvedAttributeSet) NamePrefix() string {
+	if rs.parent != nil {
+		return rs.parent.NamePrefix()
+	}
+
+	if p := rs.as.NamePrefix; p != "" {
+		return p
+	}
+
+	return fmt.Sprintf("%s-a-%s-", rs.family.s.Name, rs.as.Name)
+}
+
+// Attributes returns all of the set's attributes in specification order.
+func (rs *ResolvedAttributeSet) Attributes() []*ResolvedAttribute { return rs.attrs }
+
+// Attribute returns the named attribute, or nil if it does not exist.
+func (rs *ResolvedAttributeSet) Attribute(name string) *ResolvedAttribute {
+	return rs.nameIdx[name]
+}
+
+// AttributeByID returns the attribute with numeric ID id, or nil if it does
+// not exist.
+func (rs *ResolvedAttributeSet) AttributeByID(id int) *ResolvedAttribute {
+	return rs.idIdx[id]
+}
+
+// Select returns the named attributes in the order they are listed, such as
+// the attributes of an OperationAttributesList. Names which do not exist in
+// the set are skipped.
+func (rs *ResolvedAttributeSet) Select(names []string) []*ResolvedAttribute {
+	ras := make([]*ResolvedAttribute, 0, len(names))
+	for _, n := range names {
+		if ra := rs.nameIdx[n]; ra != nil {
+			ras = append(ras, ra)
+		}
+	}
+
+	return ras
+}
+
+// A ResolvedAttribute is an Attribute within a ResolvedAttributeSet.
+type ResolvedAttribute struct {
+	a        *Attribute
+	set      *ResolvedAttributeSet
+	nested   *ResolvedAttributeSet
+	enum     *Definition
+	strct    *Definition
+	sub      *SubMessage
+	selector *ResolvedAttribute
+	limits   Limits
+}
+
+// Attribute returns the underlying Attribute.
+func (ra *ResolvedAttribute) Attribute() *Attribute { return ra.a }
+
+// Set returns the attribute set which contains the attribute.
+func (ra *ResolvedAttribute) Set() *ResolvedAttributeSet { return ra.set }
+
+// Nested returns the attribute set named by nested-attributes, or nil if the
+// attribute does not contain nested attributes.
+func (ra *ResolvedAttribute) Nested() *ResolvedAttributeSet { return ra.nested }
+
+// Enum returns the enum or flags Definition named by enum, or nil if unset.
+func (ra *ResolvedAttribute) Enum() *Definition { return ra.enum }
+
+// Struct returns the struct Definition named by struct, or nil if unset.
+func (ra *ResolvedAttribute) Struct() *Definition { return ra.strct }
+
+// SubMessage returns the SubMessage named by sub-message, or nil if unset.
+func (ra *ResolvedAttribute) SubMessage() *SubMessage { return ra.sub }
+
+// Selector returns the attribute whose value selects the format of the
+// attribute's SubMessage, or nil if unset.
+func (ra *ResolvedAttribute) Selector() *ResolvedAttribute { return ra.selector }
+
+// Limits returns the evaluated length and bounds of the attribute.
+func (ra *ResolvedAttribute) Limits() Limits { return ra.limits }
+
+// Limits contains the evaluated Len and Checks of a ResolvedAttribute.
+type Limits struct {
+	Len, Min, Max, MinLen, MaxLen, ExactLen Limit
+}
+
+// A Limit is a length or bound evaluated by Spec.Eval. Value is only valid if
+// Set is true, which it is not if the expression refers to names reported by
+// Spec.Unresolved. A bound which exceeds math.MaxInt64, such as "u64-max", sets
+// Unsigned and is stored in Uint instead of Value.
+type Limit struct {
+	Value    int64
+	Uint     uint64
+	Unsigned bool
+	Set      bool
+}
+
+// limits evaluates the Len and Checks of Attribute a.
+func (s *Spec) limits(a *Attribute) (Limits, error) {
+	var (
+		ls  Limits
+		err error
+	)
+
+	for _, l := range []struct {
+		l    *Limit
+		expr string
+	}{
+		{&ls.Len, a.Len},
+		{&ls.Min, a.Checks.Min},
+		{&ls.Max, a.Checks.Max},
+		{&ls.MinLen, a.Checks.MinLen},
+		{&ls.MaxLen, a.Checks.MaxLen},
+		{&ls.ExactLen, a.Checks.ExactLen},
+	} {
+		if *l.l, err = s.limit(l.expr); err != nil {
+			return Limits{}, err
+		}
+	}
+
+	return ls, nil
+}
+
+// limit evaluates a single expression, returning an unset Limit if expr is
+// empty or refers to names which cannot be resolved.
+func (s *Spec) limit(expr string) (Limit, error) {
+	if expr == "" {
+		return Limit{}, nil
+	}
+
+	v, err := s.eval(expr)
+	if err != nil {
+		var eerr *ExprError
+		if errors.As(err, &eerr) && len(eerr.Unresolved) > 0 {
+			return Limit{}, nil
+		}
+
+		return Limit{}, err
+	}
+
+	if !v.IsInt64() {
+		return Limit{Uint: v.Uint64(), Unsigned: true, Set: true}, nil
+	}
+
+	return Limit{Value: v.Int64(), Set: true}, nil
+}
+
+// A ResolvedOperation is an Operation within a Family.
+type ResolvedOperation struct {
+	op     *Operation
+	set    *ResolvedAttributeSet
+	notify *ResolvedOperation
+	mcgrp  *MulticastGroup
+	hdr    *Definition
+}
+
+// Operation returns the underlying Operation.
+func (ro *ResolvedOperation) Operation() *Operation { return ro.op }
+
+// AttributeSet returns the operation's attribute set, or nil if it has none.
+func (ro *ResolvedOperation) AttributeSet() *ResolvedAttributeSet { return ro.set }
+
+// Attributes returns the attributes of an OperationAttributesList of the
+// operation in the order they are listed.
+func (ro *ResolvedOperation) Attributes(list OperationAttributesList) []*ResolvedAttribute {
+	if ro.set == nil {
+		return nil
+	}
+
+	return ro.set.Select(list.Attributes)
+}
+
+// Notify returns the operation whose attributes this notification shares, or
+// nil if unset.
+func (ro *ResolvedOperation) Notify() *ResolvedOperation { return ro.notify }
+
+// MulticastGroup returns the MulticastGroup on which the operation's
+// notifications are sent, or nil if unset.
+func (ro *ResolvedOperation) MulticastGroup() *MulticastGroup { return ro.mcgrp }
+
+// FixedHeader returns the struct Definition which precedes the operation's
+// attributes, falling back to the default for all operations, or nil if
+// neither is set.
+func (ro *ResolvedOperation) FixedHeader() *Definition { return ro.hdr }
+
+// copy returns a deep copy of s. The copy shares the YAML document of s, which
+// is never modified after parsing.
+func (s *Spec) copy() *Spec {
+	c := reflect.New(reflect.TypeOf(*s))
+	deepCopy(c.Elem(), reflect.ValueOf(*s))
+
+	cs := c.Interface().(*Spec)
+	cs.node = s.node
+	return cs
+}
+
+// deepCopy recursively copies the exported contents of src into dst so that
+// the two share no memory.
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if !src.Type().Field(i).IsExported() {
+				continue
+			}
+
+			deepCopy(dst.Field(i), src.Field(i))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	default:
+		dst.Set(src)
+	}
+}
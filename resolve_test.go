@@ -0,0 +1,215 @@
+package yamlnetlink_test
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/yamlnetlink"
+)
+
+func TestSpecResolve(t *testing.T) {
+	f, err := os.Open("testdata/ethtool/ethtool.yaml")
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	s, err := yamlnetlink.Parse(f)
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	fam, err := s.Resolve()
+	if err != nil {
+		t.Fatalf("failed to resolve spec: %v", err)
+	}
+
+	get := fam.Operation("channels-get")
+	if get == nil {
+		t.Fatal("operation channels-get does not exist")
+	}
+
+	channels := fam.AttributeSet("channels")
+	if get.AttributeSet() != channels {
+		t.Fatal("channels-get does not point to the channels attribute set")
+	}
+
+	header := channels.Attribute("header")
+	if header.Nested() != fam.AttributeSet("header") {
+		t.Fatal("channels header does not point to the header attribute set")
+	}
+	if header.Set() != channels {
+		t.Fatal("channels header does not point to its own attribute set")
+	}
+
+	ntf := fam.Operation("channels-ntf")
+	if ntf.Notify() != get {
+		t.Fatal("channels-ntf does not point to channels-get")
+	}
+	if diff := cmp.Diff(fam.MulticastGroup("monitor"), ntf.MulticastGroup()); diff != "" {
+		t.Fatalf("unexpected multicast group (-want +got):\n%s", diff)
+	}
+
+	var names []string
+	for _, ra := range get.Attributes(get.Operation().Do.Request) {
+		names = append(names, ra.Attribute().Name)
+	}
+
+	if diff := cmp.Diff([]string{"header"}, names); diff != "" {
+		t.Fatalf("unexpected request attributes (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff("tx-count", fam.AttributeByID("channels", 7).Attribute().Name); diff != "" {
+		t.Fatalf("unexpected attribute by ID (-want +got):\n%s", diff)
+	}
+
+	for _, ra := range []*yamlnetlink.ResolvedAttribute{
+		fam.AttributeByID("channels", 100),
+		fam.AttributeByID("missing", 1),
+	} {
+		if ra != nil {
+			t.Fatalf("expected nil attribute, but got: %+v", ra.Attribute())
+		}
+	}
+
+	if diff := cmp.Diff("ethtool-a-channels-", channels.NamePrefix()); diff != "" {
+		t.Fatalf("unexpected name prefix (-want +got):\n%s", diff)
+	}
+
+	// The Family must not be affected by later changes to its Spec.
+	s.AttributeSets[1].Attributes[0].Name = "modified"
+	if fam.AttributeSet("channels").Attribute("header") == nil {
+		t.Fatal("modifying the Spec affected the Family")
+	}
+}
+
+func TestSpecResolveReferences(t *testing.T) {
+	const in = `
+name: test
+definitions:
+  - { name: mode, type: enum, entries: [ a, b ] }
+  - { name: hdr, type: struct, members: [ { name: x, type: u32 } ] }
+attribute-sets:
+  -
+    name: main
+    name-prefix: test-a-
+    attributes:
+      - { name: mode, type: u32, enum: mode }
+      - { name: hdr, type: binary, struct: hdr }
+  -
+    name: sub
+    subset-of: main
+    attributes:
+      - { name: mode }
+operations:
+  fixed-header: hdr
+  list:
+    - { name: get, attribute-set: sub }
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	fam, err := s.Resolve()
+	if err != nil {
+		t.Fatalf("failed to resolve spec: %v", err)
+	}
+
+	main := fam.AttributeSet("main")
+	if diff := cmp.Diff(fam.Definition("mode"), main.Attribute("mode").Enum()); diff != "" {
+		t.Fatalf("unexpected enum (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(fam.Definition("hdr"), main.Attribute("hdr").Struct()); diff != "" {
+		t.Fatalf("unexpected struct (-want +got):\n%s", diff)
+	}
+
+	sub := fam.AttributeSet("sub")
+	if sub.Parent() != main {
+		t.Fatal("sub does not point to its parent set")
+	}
+	if diff := cmp.Diff("test-a-", sub.NamePrefix()); diff != "" {
+		t.Fatalf("unexpected subset name prefix (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(1, sub.Attribute("mode").Attribute().Value); diff != "" {
+		t.Fatalf("unexpected subset attribute value (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(fam.Definition("hdr"), fam.Operation("get").FixedHeader()); diff != "" {
+		t.Fatalf("unexpected fixed header (-want +got):\n%s", diff)
+	}
+}
+
+func TestSpecResolveLimits(t *testing.T) {
+	const in = `
+name: test
+definitions:
+  - { name: max-name, type: const, value: 32 }
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: name, type: string, len: max-name - 1, checks: { min-len: 1 } }
+      - { name: prio, type: s8, checks: { min: s8-min, max: 7 } }
+      - { name: flags, type: u32 }
+      - { name: bytes, type: u64, checks: { min: 1 << 63, max: u64-max } }
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	fam, err := s.Resolve()
+	if err != nil {
+		t.Fatalf("failed to resolve spec: %v", err)
+	}
+
+	main := fam.AttributeSet("main")
+	want := []yamlnetlink.Limits{
+		{
+			Len:    yamlnetlink.Limit{Value: 31, Set: true},
+			MinLen: yamlnetlink.Limit{Value: 1, Set: true},
+		},
+		{
+			Min: yamlnetlink.Limit{Value: -128, Set: true},
+			Max: yamlnetlink.Limit{Value: 7, Set: true},
+		},
+		{},
+		{
+			Min: yamlnetlink.Limit{Uint: 1 << 63, Unsigned: true, Set: true},
+			Max: yamlnetlink.Limit{Uint: math.MaxUint64, Unsigned: true, Set: true},
+		},
+	}
+
+	var got []yamlnetlink.Limits
+	for _, ra := range main.Attributes() {
+		got = append(got, ra.Limits())
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected limits (-want +got):\n%s", diff)
+	}
+}
+
+func TestSpecResolveInvalid(t *testing.T) {
+	s := &yamlnetlink.Spec{
+		Name: "test",
+		Operations: yamlnetlink.Operations{
+			List: []yamlnetlink.Operation{{
+				Name:         "get",
+				AttributeSet: "main",
+			}},
+		},
+	}
+
+	var verrs yamlnetlink.ValidationErrors
+	if _, err := s.Resolve(); !errors.As(err, &verrs) {
+		t.Fatalf("expected ValidationErrors, but got: %v", err)
+	}
+}
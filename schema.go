@@ -0,0 +1,552 @@
+package yamlnetlink
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaFS contains the upstream JSON schemas for each netlink protocol, as
+// shipped in the kernel's Documentation/netlink directory.
+//
+//go:embed schemas/*.yaml
+var schemaFS embed.FS
+
+// ValidateSchema validates the YAML document from which s was parsed against
+// the upstream JSON schema selected by s.Protocol, which defaults to
+// "genetlink". If s was not produced by Parse, the document produced by
+// Marshal is validated instead and errors have no positions. Each schema
+// violation is reported as a ValidationError, and all violations are returned
+// as ValidationErrors.
+func (s *Spec) ValidateSchema() error {
+	n := s.node
+	if n == nil {
+		n = s.document(false)
+	}
+
+	protocol := s.Protocol
+	if protocol == "" {
+		protocol = "genetlink"
+	}
+
+	sc, err := loadSchema(protocol)
+	if err != nil {
+		return err
+	}
+
+	var errs ValidationErrors
+	sc.validate(sc, n, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+var (
+	schemasMu sync.Mutex
+	schemas   = make(map[string]*schema)
+)
+
+// loadSchema loads and caches the embedded schema for protocol.
+func loadSchema(protocol string) (*schema, error) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+
+	if sc, ok := schemas[protocol]; ok {
+		return sc, nil
+	}
+
+	b, err := schemaFS.ReadFile("schemas/" + protocol + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("yamlnetlink: no schema for protocol %q", protocol)
+	}
+
+	// The upstream schemas begin with a YAML 1.2 directive, which the YAML
+	// package rejects despite being able to parse the documents themselves.
+	b = regexp.MustCompile(`(?m)^%YAML .*$`).ReplaceAll(b, nil)
+
+	var sc schema
+	if err := yaml.Unmarshal(b, &sc); err != nil {
+		return nil, fmt.Errorf("yamlnetlink: failed to parse schema for protocol %q: %v", protocol, err)
+	}
+
+	schemas[protocol] = &sc
+	return &sc, nil
+}
+
+// A schema is a JSON schema. Only the subset of JSON schema keywords needed by
+// the upstream netlink schemas is supported, and it is evaluated directly over
+// YAML nodes.
+type schema struct {
+	// Boolean schemas accept (true) or reject (false) every value.
+	boolean *bool
+
+	Ref  string             `yaml:"$ref"`
+	Defs map[string]*schema `yaml:"$defs"`
+
+	Type    schemaTypes `yaml:"type"`
+	Enum    []yaml.Node `yaml:"enum"`
+	Const   yaml.Node   `yaml:"const"`
+	Pattern string      `yaml:"pattern"`
+	Minimum *float64    `yaml:"minimum"`
+	Maximum *float64    `yaml:"maximum"`
+
+	Properties           map[string]*schema  `yaml:"properties"`
+	AdditionalProperties *schema             `yaml:"additionalProperties"`
+	Required             []string            `yaml:"required"`
+	Dependencies         map[string]*schema  `yaml:"dependencies"`
+	DependentRequired    map[string][]string `yaml:"dependentRequired"`
+
+	Items    *schema `yaml:"items"`
+	MinItems *int    `yaml:"minItems"`
+
+	AllOf []*schema `yaml:"allOf"`
+	AnyOf []*schema `yaml:"anyOf"`
+	OneOf []*schema `yaml:"oneOf"`
+	Not   *schema   `yaml:"not"`
+	If    *schema   `yaml:"if"`
+	Then  *schema   `yaml:"then"`
+	Else  *schema   `yaml:"else"`
+
+	re *regexp.Regexp
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (sc *schema) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!bool" {
+		b, err := strconv.ParseBool(n.Value)
+		if err != nil {
+			return err
+		}
+
+		*sc = schema{boolean: &b}
+		return nil
+	}
+
+	// Use a type without methods to avoid infinite recursion.
+	type rawSchema schema
+	var v rawSchema
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	*sc = schema(v)
+
+	if sc.Pattern != "" {
+		re, err := regexp.Compile(sc.Pattern)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid pattern: %v", n.Line, err)
+		}
+
+		sc.re = re
+	}
+
+	return nil
+}
+
+// schemaTypes is the value of the JSON schema type keyword, which may be either
+// a single type or a list of types.
+type schemaTypes []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (st *schemaTypes) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*st = schemaTypes{n.Value}
+		return nil
+	}
+
+	var ss []string
+	if err := n.Decode(&ss); err != nil {
+		return err
+	}
+
+	*st = ss
+	return nil
+}
+
+// validate validates n against sc, appending any violations to errs. root is
+// the root schema used to resolve references, and path is the location of n
+// within the document.
+func (sc *schema) validate(root *schema, n *yaml.Node, path string, errs *ValidationErrors) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			sc.validate(root, n.Content[0], path, errs)
+		}
+		return
+	case yaml.AliasNode:
+		sc.validate(root, n.Alias, path, errs)
+		return
+	}
+
+	errorf := func(format string, a ...any) {
+		*errs = append(*errs, &ValidationError{
+			Path:    displayPath(path),
+			Line:    n.Line,
+			Column:  n.Column,
+			Message: fmt.Sprintf(format, a...),
+		})
+	}
+
+	if sc.boolean != nil {
+		if !*sc.boolean {
+			errorf("value is not allowed")
+		}
+		return
+	}
+
+	if sc.Ref != "" {
+		ref, err := root.resolve(sc.Ref)
+		if err != nil {
+			errorf("%v", err)
+			return
+		}
+
+		ref.validate(root, n, path, errs)
+	}
+
+	typ := nodeType(n)
+	if len(sc.Type) > 0 && !sc.Type.match(typ) {
+		errorf("expected type %s, but got %s", strings.Join(sc.Type, " or "), typ)
+		// Further checks are meaningless when the type is wrong.
+		return
+	}
+
+	if len(sc.Enum) > 0 {
+		var (
+			ok   bool
+			vals = make([]string, 0, len(sc.Enum))
+		)
+
+		for i := range sc.Enum {
+			e := &sc.Enum[i]
+			vals = append(vals, e.Value)
+			if nodeEqual(e, n) {
+				ok = true
+			}
+		}
+
+		if !ok {
+			errorf("value %q is not one of [%s]", n.Value, strings.Join(vals, ", "))
+		}
+	}
+
+	if sc.Const.Kind != 0 && !nodeEqual(&sc.Const, n) {
+		errorf("value %q must be %q", n.Value, sc.Const.Value)
+	}
+
+	switch typ {
+	case "string":
+		if sc.re != nil && !sc.re.MatchString(n.Value) {
+			errorf("value %q does not match pattern %q", n.Value, sc.Pattern)
+		}
+	case "integer", "number":
+		f, _ := nodeNumber(n)
+		if sc.Minimum != nil && f < *sc.Minimum {
+			errorf("value %s is less than minimum %v", n.Value, *sc.Minimum)
+		}
+		if sc.Maximum != nil && f > *sc.Maximum {
+			errorf("value %s is greater than maximum %v", n.Value, *sc.Maximum)
+		}
+	case "object":
+		sc.validateObject(root, n, path, errs, errorf)
+	case "array":
+		if sc.MinItems != nil && len(n.Content) < *sc.MinItems {
+			errorf("expected at least %d items, but got %d", *sc.MinItems, len(n.Content))
+		}
+
+		if sc.Items != nil {
+			for i, c := range n.Content {
+				sc.Items.validate(root, c, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+
+	for _, s := range sc.AllOf {
+		s.validate(root, n, path, errs)
+	}
+
+	if len(sc.AnyOf) > 0 {
+		var best ValidationErrors
+		for i, s := range sc.AnyOf {
+			var serrs ValidationErrors
+			s.validate(root, n, path, &serrs)
+			if len(serrs) == 0 {
+				best = nil
+				break
+			}
+			if i == 0 || len(serrs) < len(best) {
+				best = serrs
+			}
+		}
+
+		// Report the errors of the closest matching schema.
+		*errs = append(*errs, best...)
+	}
+
+	if len(sc.OneOf) > 0 {
+		var (
+			matches int
+			best    ValidationErrors
+		)
+
+		for i, s := range sc.OneOf {
+			var serrs ValidationErrors
+			s.validate(root, n, path, &serrs)
+			if len(serrs) == 0 {
+				matches++
+				continue
+			}
+			if i == 0 || best == nil || len(serrs) < len(best) {
+				best = serrs
+			}
+		}
+
+		switch matches {
+		case 0:
+			*errs = append(*errs, best...)
+		case 1:
+		default:
+			errorf("value matches %d schemas, but must match exactly one", matches)
+		}
+	}
+
+	if sc.Not != nil {
+		var serrs ValidationErrors
+		sc.Not.validate(root, n, path, &serrs)
+		if len(serrs) == 0 {
+			errorf("value matches a schema which it must not match")
+		}
+	}
+
+	if sc.If != nil {
+		var serrs ValidationErrors
+		sc.If.validate(root, n, path, &serrs)
+
+		switch {
+		case len(serrs) == 0 && sc.Then != nil:
+			sc.Then.validate(root, n, path, errs)
+		case len(serrs) > 0 && sc.Else != nil:
+			sc.Else.validate(root, n, path, errs)
+		}
+	}
+}
+
+// validateObject validates the object-specific keywords of sc against mapping
+// node n.
+func (sc *schema) validateObject(
+	root *schema,
+	n *yaml.Node,
+	path string,
+	errs *ValidationErrors,
+	errorf func(format string, a ...any),
+) {
+	keys, values := mappingPairs(n)
+
+	present := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		present[k.Value] = true
+	}
+
+	for _, r := range sc.Required {
+		if !present[r] {
+			errorf("missing required property %q", r)
+		}
+	}
+
+	// Iterate over dependencies in a fixed order for stable output.
+	for _, k := range sortedKeys(sc.DependentRequired) {
+		if !present[k] {
+			continue
+		}
+
+		for _, d := range sc.DependentRequired[k] {
+			if !present[d] {
+				errorf("property %q requires property %q", k, d)
+			}
+		}
+	}
+
+	for _, k := range sortedKeys(sc.Dependencies) {
+		if present[k] {
+			sc.Dependencies[k].validate(root, n, path, errs)
+		}
+	}
+
+	for i, k := range keys {
+		kpath := k.Value
+		if path != "" {
+			kpath = path + "." + k.Value
+		}
+
+		if ps, ok := sc.Properties[k.Value]; ok {
+			ps.validate(root, values[i], kpath, errs)
+			continue
+		}
+
+		if sc.AdditionalProperties == nil {
+			continue
+		}
+
+		if b := sc.AdditionalProperties.boolean; b != nil && !*b {
+			*errs = append(*errs, &ValidationError{
+				Path:    kpath,
+				Line:    k.Line,
+				Column:  k.Column,
+				Message: fmt.Sprintf("unknown property %q", k.Value),
+			})
+			continue
+		}
+
+		sc.AdditionalProperties.validate(root, values[i], kpath, errs)
+	}
+}
+
+// resolve resolves a local JSON pointer reference such as "#/$defs/uint"
+// against the root schema sc.
+func (sc *schema) resolve(ref string) (*schema, error) {
+	const prefix = "#/$defs/"
+	if !strings.HasPrefix(ref, prefix) {
+		return nil, fmt.Errorf("unsupported schema reference %q", ref)
+	}
+	name := strings.TrimPrefix(ref, prefix)
+
+	s, ok := sc.Defs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema reference %q", ref)
+	}
+
+	return s, nil
+}
+
+// match reports whether JSON type typ satisfies st.
+func (st schemaTypes) match(typ string) bool {
+	for _, t := range st {
+		// All integers are also numbers.
+		if t == typ || (t == "number" && typ == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nodeType returns the JSON type of a YAML node.
+func nodeType(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+
+	switch n.ShortTag() {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		// Floats with integral values are integers in JSON schema.
+		if f, ok := nodeNumber(n); ok && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return "null"
+	default:
+		return "string"
+	}
+}
+
+// nodeNumber returns the numeric value of a scalar YAML node.
+func nodeNumber(n *yaml.Node) (float64, bool) {
+	var f float64
+	if err := n.Decode(&f); err != nil {
+		return 0, false
+	}
+
+	return f, true
+}
+
+// nodeEqual reports whether two scalar YAML nodes have equal values.
+func nodeEqual(a, b *yaml.Node) bool {
+	ta, tb := nodeType(a), nodeType(b)
+	switch {
+	case ta == "integer" || ta == "number":
+		if tb != "integer" && tb != "number" {
+			return false
+		}
+
+		fa, _ := nodeNumber(a)
+		fb, _ := nodeNumber(b)
+		return fa == fb
+	case ta != tb:
+		return false
+	case ta == "boolean":
+		var va, vb bool
+		_ = a.Decode(&va)
+		_ = b.Decode(&vb)
+		return va == vb
+	default:
+		return a.Value == b.Value
+	}
+}
+
+// mappingPairs returns the keys and values of mapping node n, expanding any
+// YAML merge keys.
+func mappingPairs(n *yaml.Node) (keys, values []*yaml.Node) {
+	for i := 0; i < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.Tag != "!!merge" {
+			keys = append(keys, k)
+			values = append(values, v)
+			continue
+		}
+
+		// A merge value is either a single mapping or a sequence of them.
+		merges := []*yaml.Node{v}
+		if v.Kind == yaml.SequenceNode {
+			merges = v.Content
+		}
+
+		for _, m := range merges {
+			if m.Kind == yaml.AliasNode {
+				m = m.Alias
+			}
+
+			mk, mv := mappingPairs(m)
+			keys = append(keys, mk...)
+			values = append(values, mv...)
+		}
+	}
+
+	return keys, values
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// displayPath returns the path for the document root as "." and otherwise
+// returns path unmodified.
+func displayPath(path string) string {
+	if path == "" {
+		return "."
+	}
+
+	return path
+}
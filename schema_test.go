@@ -0,0 +1,165 @@
+package yamlnetlink_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/yamlnetlink"
+)
+
+func TestSpecValidateSchemaOK(t *testing.T) {
+	for _, family := range []string{"ethtool", "nlctrl"} {
+		t.Run(family, func(t *testing.T) {
+			f, err := os.Open("testdata/" + family + "/" + family + ".yaml")
+			if err != nil {
+				t.Fatalf("failed to open file: %v", err)
+			}
+			defer f.Close()
+
+			s, err := yamlnetlink.Parse(f)
+			if err != nil {
+				t.Fatalf("failed to parse YAML: %v", err)
+			}
+
+			if err := s.ValidateSchema(); err != nil {
+				t.Fatalf("failed to validate schema: %v", err)
+			}
+		})
+	}
+}
+
+func TestSpecValidateSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want yamlnetlink.ValidationErrors
+	}{
+		{
+			name: "genetlink",
+			in: `
+name: test
+doc: Test family.
+definitions:
+  - { name: hdr, type: struct }
+attribute-sets:
+  -
+    name: main
+    attr-cnt-name: __TEST_A_CNT
+    attributes:
+      - { name: foo, type: u128 }
+      - { name: bar, type: u32, value: -1 }
+      - { name: baz, checks: { max-len: "not a define" } }
+operations:
+  list:
+    - { name: get, doc: Get., fixed-header: hdr }
+`,
+			want: yamlnetlink.ValidationErrors{
+				{
+					Path:    "definitions[0].type",
+					Line:    5,
+					Column:  24,
+					Message: `value "struct" is not one of [const, enum, flags]`,
+				},
+				{
+					Path:    "attribute-sets[0].attr-cnt-name",
+					Line:    9,
+					Column:  5,
+					Message: `unknown property "attr-cnt-name"`,
+				},
+				{
+					Path:    "attribute-sets[0].attributes[0].type",
+					Line:    11,
+					Column:  28,
+					Message: `value "u128" is not one of [unused, pad, flag, binary, bitfield32, uint, sint, u8, u16, u32, u64, s8, s16, s32, s64, string, nest, indexed-array, nest-type-value]`,
+				},
+				{
+					Path:    "attribute-sets[0].attributes[1].value",
+					Line:    12,
+					Column:  40,
+					Message: "value -1 is less than minimum 0",
+				},
+				{
+					Path:    "attribute-sets[0].attributes[2].checks.max-len",
+					Line:    13,
+					Column:  41,
+					Message: `value "not a define" does not match pattern "^[0-9A-Za-z_-]+( - 1)?$"`,
+				},
+				{
+					Path:    "attribute-sets[0].attributes[2]",
+					Line:    13,
+					Column:  9,
+					Message: `missing required property "type"`,
+				},
+				{
+					Path:    "operations.list[0].fixed-header",
+					Line:    16,
+					Column:  31,
+					Message: `unknown property "fixed-header"`,
+				},
+			},
+		},
+		{
+			name: "netlink-raw",
+			in: `
+name: test
+protocol: netlink-raw
+protonum: 0
+doc: Test family.
+attribute-sets: []
+operations:
+  list: []
+sub-messages:
+  - { name: msg, formats: [ { attribute-set: foo } ] }
+mcast-groups:
+  list:
+    - { name: grp, value: -1 }
+`,
+			want: yamlnetlink.ValidationErrors{
+				{
+					Path:    "sub-messages[0].formats[0]",
+					Line:    10,
+					Column:  29,
+					Message: `missing required property "value"`,
+				},
+				{
+					Path:    "mcast-groups.list[0].value",
+					Line:    13,
+					Column:  27,
+					Message: "value -1 is less than minimum 0",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := yamlnetlink.Parse(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("failed to parse YAML: %v", err)
+			}
+
+			var got yamlnetlink.ValidationErrors
+			if err := s.ValidateSchema(); !errors.As(err, &got) {
+				t.Fatalf("expected ValidationErrors, but got: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("unexpected ValidationErrors (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSpecValidateSchemaUnknownProtocol(t *testing.T) {
+	s, err := yamlnetlink.Parse(strings.NewReader("name: test\nprotocol: carrier-pigeon\n"))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	if err := s.ValidateSchema(); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+}
@@ -6,7 +6,9 @@
 package yamlnetlink
 
 import (
+	"fmt"
 	"io"
+	"reflect"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -14,76 +16,639 @@ import (
 
 // A Spec is a YAML netlink specification.
 type Spec struct {
-	Name          string         `yaml:"name"`
-	Protocol      string         `yaml:"protocol"`
-	Doc           string         `yaml:"doc"`
-	UAPIHeader    string         `yaml:"uapi-header"`
-	AttributeSets []AttributeSet `yaml:"attribute-sets"`
-	Operations    Operations     `yaml:"operations"`
+	Name            string          `yaml:"name"`
+	Protocol        string          `yaml:"protocol"`
+	Protonum        int             `yaml:"protonum"`
+	Doc             Doc             `yaml:"doc"`
+	UAPIHeader      string          `yaml:"uapi-header"`
+	Definitions     []Definition    `yaml:"definitions"`
+	AttributeSets   []AttributeSet  `yaml:"attribute-sets"`
+	Operations      Operations      `yaml:"operations"`
+	MulticastGroups MulticastGroups `yaml:"mcast-groups"`
+	SubMessages     []SubMessage    `yaml:"sub-messages"`
+
+	// node is the parsed YAML document, if any, used to report the positions
+	// of problems found after parsing.
+	node *yaml.Node
+}
+
+// Parse parses a YAML netlink specification into a Spec. Numeric values which
+// are implicit in the specification, such as attribute and command IDs, are
+// computed according to the specification's numbering rules. Explicit values
+// may be constant expressions, as accepted by Spec.Eval, which refer to const
+// definitions.
+func Parse(r io.Reader) (*Spec, error) { return ParseWithOptions(r, nil) }
+
+// ParseOptions specifies options for ParseWithOptions.
+type ParseOptions struct {
+	// Strict specifies that any key in the specification which is not
+	// modeled by Spec is an error. By default, unknown keys are ignored.
+	Strict bool
+}
+
+// ParseWithOptions parses a YAML netlink specification into a Spec in the same
+// way as Parse, but with additional options. If opts is nil, a default
+// ParseOptions is used.
+//
+// In strict mode, any unknown key results in an *UnknownFieldError.
+func ParseWithOptions(r io.Reader, opts *ParseOptions) (*Spec, error) {
+	if opts == nil {
+		opts = &ParseOptions{}
+	}
+
+	var n yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&n); err != nil {
+		return nil, err
+	}
+
+	return parse(&n, opts)
 }
 
-// Parse parses a YAML netlink specification into a Spec.
-func Parse(r io.Reader) (*Spec, error) {
+// parse decodes a Spec from YAML document n.
+func parse(n *yaml.Node, opts *ParseOptions) (*Spec, error) {
+	if opts.Strict {
+		if err := knownFields(n, reflect.TypeOf(Spec{}), ""); err != nil {
+			return nil, err
+		}
+	}
+
 	var s Spec
-	if err := yaml.NewDecoder(r).Decode(&s); err != nil {
+	if err := n.Decode(&s); err != nil {
 		return nil, err
 	}
 
-	// After decoding, clean up strings so they're more machine friendly.
-	s.sanitize()
+	s.node = n
 
 	return &s, nil
 }
 
-// sanitize tidies a Spec's documentation strings in-place.
-func (s *Spec) sanitize() {
-	sanitize(&s.Doc)
+// An UnknownFieldError is returned by ParseWithOptions in strict mode when a
+// specification contains a key which is not modeled by Spec.
+type UnknownFieldError struct {
+	// Path is the location of the key within the specification, such as
+	// "attribute-sets[0].attributes[1].foo".
+	Path string
+
+	// Field is the unknown key.
+	Field string
+
+	// Line and Column are the position of the key in the YAML document.
+	Line, Column int
+}
+
+// Error implements error.
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("yamlnetlink: line %d, column %d: unknown field %q at %s",
+		e.Line, e.Column, e.Field, e.Path)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *Spec) UnmarshalYAML(n *yaml.Node) error {
+	// Values may be constant expressions, which are evaluated before decoding.
+	vn, err := evalValues(n)
+	if err != nil {
+		return err
+	}
+
+	// Use a type without methods to avoid infinite recursion.
+	type spec Spec
+	var v spec
+	if err := vn.Decode(&v); err != nil {
+		return err
+	}
+	*s = Spec(v)
+
+	if err := s.inheritSubsets(); err != nil {
+		return err
+	}
+
+	// Operation numbering depends on both the protocol and the enum model, so
+	// it must be computed with the entire Spec in view.
+	return s.Operations.number(s.Protocol, valueOf(n, "operations"))
+}
+
+// inheritSubsets fills in the attributes of each AttributeSet which is a subset
+// of another with the fields of the corresponding attributes in the parent set.
+// Unknown parent sets and attributes are skipped and reported by Validate.
+func (s *Spec) inheritSubsets() error {
+	idx := make(map[string]int, len(s.AttributeSets))
+	for i, as := range s.AttributeSets {
+		idx[as.Name] = i
+	}
+
+	// Subsets may themselves be subsets of other sets, so resolve parents first
+	// and track the sets currently being resolved to detect cycles.
+	var (
+		done    = make(map[string]bool)
+		walking = make(map[string]bool)
+		inherit func(name string) error
+	)
+
+	inherit = func(name string) error {
+		as := &s.AttributeSets[idx[name]]
+		if as.SubsetOf == "" || done[name] {
+			return nil
+		}
+		if walking[name] {
+			return fmt.Errorf("yamlnetlink: attribute set %q has a subset-of cycle", name)
+		}
+		walking[name] = true
+
+		pi, ok := idx[as.SubsetOf]
+		if !ok {
+			done[name] = true
+			return nil
+		}
+		if err := inherit(as.SubsetOf); err != nil {
+			return err
+		}
+
+		parent := s.AttributeSets[pi]
+		for i := range as.Attributes {
+			a := &as.Attributes[i]
+			if pa, ok := parent.attribute(a.Name); ok {
+				a.inherit(pa)
+			}
+		}
+
+		done[name] = true
+		return nil
+	}
+
+	for _, as := range s.AttributeSets {
+		if err := inherit(as.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// A DefinitionType is the type of a Definition.
+type DefinitionType string
+
+// Possible DefinitionType values.
+const (
+	DefinitionConst  DefinitionType = "const"
+	DefinitionEnum   DefinitionType = "enum"
+	DefinitionFlags  DefinitionType = "flags"
+	DefinitionStruct DefinitionType = "struct"
+)
+
+// A Definition describes a constant, enum, flags, or struct type which is
+// referenced by a netlink family's attributes and operations.
+type Definition struct {
+	Name   string         `yaml:"name"`
+	Type   DefinitionType `yaml:"type"`
+	Header string         `yaml:"header"`
+	Doc    Doc            `yaml:"doc"`
+
+	// Value is the value of a DefinitionConst.
+	Value int `yaml:"value"`
+
+	// Fields used by DefinitionEnum and DefinitionFlags.
+	NamePrefix string      `yaml:"name-prefix"`
+	EnumName   string      `yaml:"enum-name"`
+	ValueStart int         `yaml:"value-start"`
+	RenderMax  bool        `yaml:"render-max"`
+	Entries    []EnumEntry `yaml:"entries"`
+
+	// Members are the fields of a DefinitionStruct.
+	Members []StructMember `yaml:"members"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Definition) UnmarshalYAML(n *yaml.Node) error {
+	// Use a type without methods to avoid infinite recursion.
+	type definition Definition
+	var v definition
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	*d = Definition(v)
 
-	for i := range s.AttributeSets {
-		for j := range s.AttributeSets[i].Attributes {
-			sanitize(&s.AttributeSets[i].Attributes[j].Doc)
+	entries := valueOf(n, "entries")
+	if entries == nil {
+		return nil
+	}
+
+	// Each entry without an explicit value follows the previous entry,
+	// beginning at ValueStart.
+	next := d.ValueStart
+	for i := range d.Entries {
+		if hasKey(entries.Content[i], "value") {
+			next = d.Entries[i].Value
 		}
+
+		d.Entries[i].Value = next
+		next++
 	}
+
+	return nil
+}
+
+// An EnumEntry is a single named value within an enum or flags Definition.
+type EnumEntry struct {
+	Name string `yaml:"name"`
+	Doc  Doc    `yaml:"doc"`
+
+	// Value is the numeric value of the entry. For DefinitionFlags, Value is
+	// the bit number of the flag rather than its mask. If unset in the
+	// specification, Parse computes Value from the entry's position.
+	Value int `yaml:"value"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. Entries may be specified either
+// as a plain name or as a mapping with additional fields.
+func (e *EnumEntry) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*e = EnumEntry{Name: n.Value}
+		return nil
+	}
+
+	// Use a type without methods to avoid infinite recursion.
+	type entry EnumEntry
+	var v entry
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+
+	*e = EnumEntry(v)
+	return nil
+}
+
+// A StructMember is a single field within a struct Definition.
+type StructMember struct {
+	Name        string      `yaml:"name"`
+	Type        string      `yaml:"type"`
+	Len         string      `yaml:"len"`
+	Doc         Doc         `yaml:"doc"`
+	ByteOrder   ByteOrder   `yaml:"byte-order"`
+	DisplayHint DisplayHint `yaml:"display-hint"`
+	Enum        string      `yaml:"enum"`
+	EnumAsFlags bool        `yaml:"enum-as-flags"`
 }
 
 // An AttributeSet describes the netlink attributes for a given family.
 type AttributeSet struct {
-	Name       string      `yaml:"name"`
-	NamePrefix string      `yaml:"name-prefix"`
-	Attributes []Attribute `yaml:"attributes"`
+	Name        string      `yaml:"name"`
+	NamePrefix  string      `yaml:"name-prefix"`
+	SubsetOf    string      `yaml:"subset-of"`
+	AttrCntName string      `yaml:"attr-cnt-name"`
+	AttrMaxName string      `yaml:"attr-max-name"`
+	Attributes  []Attribute `yaml:"attributes"`
 }
 
-// An Attribute describes a single netlink attribute.
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (as *AttributeSet) UnmarshalYAML(n *yaml.Node) error {
+	// Use a type without methods to avoid infinite recursion.
+	type attributeSet AttributeSet
+	var v attributeSet
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	*as = AttributeSet(v)
+
+	attrs := valueOf(n, "attributes")
+	if attrs == nil || as.SubsetOf != "" {
+		// Subsets inherit their values from the parent set.
+		return nil
+	}
+
+	// Each attribute without an explicit value follows the previous attribute,
+	// beginning at 1 since 0 is reserved for the unspecified attribute.
+	next := 1
+	for i := range as.Attributes {
+		if hasKey(attrs.Content[i], "value") {
+			next = as.Attributes[i].Value
+		}
+
+		as.Attributes[i].Value = next
+		next++
+	}
+
+	return nil
+}
+
+// attribute returns the Attribute with the specified name.
+func (as AttributeSet) attribute(name string) (Attribute, bool) {
+	for _, a := range as.Attributes {
+		if a.Name == name {
+			return a, true
+		}
+	}
+
+	return Attribute{}, false
+}
+
+// MaxValue returns the highest attribute ID in the AttributeSet, matching the
+// value of the set's attr-max-name constant.
+func (as AttributeSet) MaxValue() int {
+	var max int
+	for _, a := range as.Attributes {
+		if a.Value > max {
+			max = a.Value
+		}
+	}
+
+	return max
+}
+
+// An Attribute describes a single netlink attribute. Value is the numeric ID
+// of the attribute within its AttributeSet.
 type Attribute struct {
 	Name             string   `yaml:"name"`
 	Type             string   `yaml:"type"`
+	Value            int      `yaml:"value"`
 	TypeValue        []string `yaml:"type-value"`
 	Len              string   `yaml:"len"`
-	Doc              string   `yaml:"doc"`
+	Doc              Doc      `yaml:"doc"`
 	NestedAttributes string   `yaml:"nested-attributes"`
+	Checks           Checks   `yaml:"checks"`
+
+	// Encoding hints which describe how the attribute's payload is laid out
+	// and should be presented.
+	ByteOrder   ByteOrder   `yaml:"byte-order"`
+	DisplayHint DisplayHint `yaml:"display-hint"`
+	SubType     string      `yaml:"sub-type"`
+	Struct      string      `yaml:"struct"`
+	MultiAttr   bool        `yaml:"multi-attr"`
+	Enum        string      `yaml:"enum"`
+	EnumAsFlags bool        `yaml:"enum-as-flags"`
+
+	// SubMessage names the SubMessage which describes the attribute's payload
+	// in netlink-raw families. Selector names a sibling attribute whose value
+	// selects the SubMessageFormat.
+	SubMessage string `yaml:"sub-message"`
+	Selector   string `yaml:"selector"`
+}
+
+// A ByteOrder is the byte order of an integer attribute or struct member. The
+// empty ByteOrder indicates native byte order.
+type ByteOrder string
+
+// Possible ByteOrder values.
+const (
+	BigEndian    ByteOrder = "big-endian"
+	LittleEndian ByteOrder = "little-endian"
+)
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (bo *ByteOrder) UnmarshalYAML(n *yaml.Node) error {
+	switch v := ByteOrder(n.Value); v {
+	case BigEndian, LittleEndian:
+		*bo = v
+		return nil
+	}
+
+	return fmt.Errorf("yamlnetlink: line %d: unknown byte-order %q", n.Line, n.Value)
+}
+
+// A DisplayHint describes how a value should be presented to users.
+type DisplayHint string
+
+// Possible DisplayHint values.
+const (
+	Hex      DisplayHint = "hex"
+	MAC      DisplayHint = "mac"
+	FDDI     DisplayHint = "fddi"
+	IPv4     DisplayHint = "ipv4"
+	IPv6     DisplayHint = "ipv6"
+	IPv4OrV6 DisplayHint = "ipv4-or-v6"
+	UUID     DisplayHint = "uuid"
+)
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (dh *DisplayHint) UnmarshalYAML(n *yaml.Node) error {
+	switch v := DisplayHint(n.Value); v {
+	case Hex, MAC, FDDI, IPv4, IPv6, IPv4OrV6, UUID:
+		*dh = v
+		return nil
+	}
+
+	return fmt.Errorf("yamlnetlink: line %d: unknown display-hint %q", n.Line, n.Value)
+}
+
+// inherit sets the Value of a to that of parent, and fills each other unset
+// field of a with the corresponding field of parent.
+func (a *Attribute) inherit(parent Attribute) {
+	var (
+		dst = reflect.ValueOf(a).Elem()
+		src = reflect.ValueOf(parent)
+	)
+
+	for i := 0; i < dst.NumField(); i++ {
+		if f := dst.Field(i); f.IsZero() {
+			f.Set(src.Field(i))
+		}
+	}
+
+	a.Value = parent.Value
+}
+
+// Checks describes the kernel's validation constraints for an Attribute's
+// value. Numeric fields are constant expressions in the same form as
+// Attribute.Len, which can be evaluated by Spec.Eval, and are empty when unset.
+type Checks struct {
+	// Min and Max bound the value of an integer attribute.
+	Min string `yaml:"min"`
+	Max string `yaml:"max"`
+
+	// MinLen, MaxLen, and ExactLen bound the length of a string or binary
+	// attribute.
+	MinLen   string `yaml:"min-len"`
+	MaxLen   string `yaml:"max-len"`
+	ExactLen string `yaml:"exact-len"`
+
+	// UnterminatedOK permits a string attribute without a NUL terminator.
+	UnterminatedOK bool `yaml:"unterminated-ok"`
 }
 
 // Operations describes the request and reply operations available for a netlink
-// family.
+// family. In netlink-raw families, FixedHeader names the struct Definition
+// which precedes the attributes of each message, unless overridden by
+// Operation.FixedHeader.
 type Operations struct {
-	NamePrefix string      `yaml:"name-prefix"`
-	List       []Operation `yaml:"list"`
+	NamePrefix  string      `yaml:"name-prefix"`
+	EnumModel   EnumModel   `yaml:"enum-model"`
+	AsyncPrefix string      `yaml:"async-prefix"`
+	AsyncEnum   string      `yaml:"async-enum"`
+	FixedHeader string      `yaml:"fixed-header"`
+	List        []Operation `yaml:"list"`
+}
+
+// An EnumModel specifies how command IDs are assigned to Operations.
+type EnumModel string
+
+// Possible EnumModel values.
+const (
+	// EnumModelUnified uses a single command ID for requests, replies, and
+	// notifications. It is the default for all protocols.
+	EnumModelUnified EnumModel = "unified"
+
+	// EnumModelDirectional numbers requests to the kernel separately from
+	// replies and notifications sent by the kernel. It is only supported by
+	// the genetlink-legacy and netlink-raw protocols.
+	EnumModelDirectional EnumModel = "directional"
+)
+
+// number computes the implicit command IDs of each Operation in-place. n is the
+// YAML node for the operations mapping and is used to determine which values
+// were set explicitly.
+func (ops *Operations) number(protocol string, n *yaml.Node) error {
+	list := valueOf(n, "list")
+	if list == nil {
+		return nil
+	}
+
+	switch ops.EnumModel {
+	case "", EnumModelUnified:
+		// Each operation without an explicit value follows the previous
+		// operation, beginning at 1 since 0 is reserved.
+		next := 1
+		for i := range ops.List {
+			op := &ops.List[i]
+			if hasKey(list.Content[i], "value") {
+				next = op.Value
+			}
+
+			op.Value, op.ReplyValue = next, next
+			next++
+		}
+
+		return nil
+	case EnumModelDirectional:
+		switch protocol {
+		case "genetlink-legacy", "netlink-raw":
+		default:
+			return fmt.Errorf("yamlnetlink: enum-model %q is not supported by protocol %q",
+				ops.EnumModel, protocol)
+		}
+	default:
+		return fmt.Errorf("yamlnetlink: unknown enum-model %q", ops.EnumModel)
+	}
+
+	// Requests and replies are numbered independently, following the rules of
+	// the kernel's ynl tooling. Notifications only consume reply IDs and take
+	// an explicit ID from the operation-level value, while commands always
+	// consume a request ID and consume a reply ID only when they produce
+	// replies. The IDs of commands are taken only from the request and reply
+	// values of the first of do or dump; an operation-level value has no
+	// effect on them.
+	req, rep := 1, 1
+	for i := range ops.List {
+		var (
+			op    = &ops.List[i]
+			node  = list.Content[i]
+			value = hasKey(node, "value")
+		)
+
+		if op.Notify != "" || hasKey(node, "event") {
+			if value {
+				rep = op.Value
+			}
+
+			op.Value, op.ReplyValue = 0, rep
+			rep++
+			continue
+		}
+
+		// The first of do or dump determines the values for both.
+		oas, mode := op.Do, valueOf(node, "do")
+		if mode == nil {
+			oas, mode = op.Dump, valueOf(node, "dump")
+		}
+		if mode == nil {
+			return fmt.Errorf("yamlnetlink: directional operation %q must specify do, dump, notify, or event",
+				op.Name)
+		}
+
+		if hasKey(valueOf(mode, "request"), "value") {
+			req = oas.Request.Value
+		}
+		if hasKey(valueOf(mode, "reply"), "value") {
+			rep = oas.Reply.Value
+		}
+
+		op.Value, op.ReplyValue = req, 0
+		req++
+
+		if hasKey(mode, "reply") {
+			op.ReplyValue = rep
+			rep++
+		}
+	}
+
+	return nil
 }
 
 // An Operation describes a single netlink request/reply operation.
 type Operation struct {
-	Name         string              `yaml:"name"`
-	Doc          string              `yaml:"doc"`
-	AttributeSet string              `yaml:"attribute-set"`
-	DontValidate []string            `yaml:"dont-validate"`
-	Notify       string              `yaml:"notify"`
-	Do           OperationAttributes `yaml:"do"`
-	Dump         OperationAttributes `yaml:"dump"`
+	Name           string              `yaml:"name"`
+	Doc            Doc                 `yaml:"doc"`
+	Value          int                 `yaml:"value"`
+	AttributeSet   string              `yaml:"attribute-set"`
+	DontValidate   []string            `yaml:"dont-validate"`
+	Notify         string              `yaml:"notify"`
+	MulticastGroup string              `yaml:"mcgrp"`
+	Flags          []OperationFlag     `yaml:"flags"`
+	ConfigCond     string              `yaml:"config-cond"`
+	FixedHeader    string              `yaml:"fixed-header"`
+	Do             OperationAttributes `yaml:"do"`
+	Dump           OperationAttributes `yaml:"dump"`
+
+	// Event describes the attributes of an asynchronous event which is not
+	// associated with a request.
+	Event OperationAttributesList `yaml:"event"`
+
+	// ReplyValue is the command ID of replies and notifications sent by the
+	// kernel. For EnumModelUnified it is always equal to Value. For
+	// EnumModelDirectional, Value is the command ID of requests to the kernel
+	// and either field is 0 if the Operation has no messages in that
+	// direction.
+	ReplyValue int `yaml:"-"`
+}
+
+// IsNotification reports whether the Operation describes a notification or
+// event sent by the kernel rather than a command.
+func (op Operation) IsNotification() bool {
+	return op.Notify != "" || len(op.Event.Attributes) > 0
 }
 
+// Privileged reports whether the Operation requires administrative
+// privileges.
+func (op Operation) Privileged() bool {
+	for _, f := range op.Flags {
+		switch f {
+		case AdminPerm, UnsAdminPerm:
+			return true
+		}
+	}
+
+	return false
+}
+
+// An OperationFlag is a flag which modifies the behavior of an Operation.
+type OperationFlag string
+
+// Possible OperationFlag values.
+const (
+	// AdminPerm requires CAP_NET_ADMIN in the initial user namespace.
+	AdminPerm OperationFlag = "admin-perm"
+
+	// UnsAdminPerm requires CAP_NET_ADMIN in the user namespace which owns
+	// the network namespace.
+	UnsAdminPerm OperationFlag = "uns-admin-perm"
+)
+
 // OperationAttributes describes the list of attributes used in netlink request
 // and replies for a given Operation.
 type OperationAttributes struct {
+	// Pre and Post optionally name kernel hooks which run before and after
+	// the operation's handler.
+	Pre  string `yaml:"pre"`
+	Post string `yaml:"post"`
+
 	Request OperationAttributesList `yaml:"request"`
 	Reply   OperationAttributesList `yaml:"reply"`
 }
@@ -91,15 +656,176 @@ type OperationAttributes struct {
 // An OperationAttributesList contains the actual attributes used in a netlink
 // request or reply operation.
 type OperationAttributesList struct {
+	// Value optionally overrides the command ID for this direction of an
+	// Operation when EnumModelDirectional is in use.
+	Value      int      `yaml:"value"`
 	Attributes []string `yaml:"attributes"`
 }
 
-// sanitize cleans up a string in-place.
-func sanitize(s *string) {
-	if s == nil {
-		panic("yamlnetlink: cannot sanitize nil string pointer")
+// MulticastGroups describes the multicast groups available for a netlink
+// family.
+type MulticastGroups struct {
+	List []MulticastGroup `yaml:"list"`
+}
+
+// A MulticastGroup describes a single netlink multicast group which may be
+// joined to receive notifications.
+type MulticastGroup struct {
+	Name        string `yaml:"name"`
+	CDefineName string `yaml:"c-define-name"`
+	Doc         Doc    `yaml:"doc"`
+
+	// Value is the group's fixed ID in netlink-raw families. Generic netlink
+	// families resolve multicast group IDs at runtime.
+	Value int `yaml:"value"`
+}
+
+// A SubMessage describes an attribute payload in netlink-raw families whose
+// format depends on the value of another attribute.
+type SubMessage struct {
+	Name    string             `yaml:"name"`
+	Formats []SubMessageFormat `yaml:"formats"`
+}
+
+// Format returns the SubMessageFormat selected by value, which is the value of
+// the selector attribute.
+func (sm SubMessage) Format(value string) (SubMessageFormat, bool) {
+	for _, f := range sm.Formats {
+		if f.Value == value {
+			return f, true
+		}
+	}
+
+	return SubMessageFormat{}, false
+}
+
+// A SubMessageFormat is a single format of a SubMessage. Value is the selector
+// value which chooses this format. The payload consists of an optional fixed
+// header struct followed by attributes from an AttributeSet.
+type SubMessageFormat struct {
+	Value        string `yaml:"value"`
+	FixedHeader  string `yaml:"fixed-header"`
+	AttributeSet string `yaml:"attribute-set"`
+}
+
+// knownFields verifies that each mapping key in n is a field of type t, and
+// recursively checks the values of those fields. path is the location of n
+// within the document.
+func knownFields(n *yaml.Node, t reflect.Type, path string) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			if err := knownFields(c, t, path); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case yaml.AliasNode:
+		return knownFields(n.Alias, t, path)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		// Types such as EnumEntry may also be decoded from non-mapping nodes,
+		// and any other mismatches are reported by the decoder.
+		if n.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		fields := yamlFields(t)
+		for i := 0; i < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				// Merge keys pull in the fields of other mappings.
+				if err := knownFields(v, t, path); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			kpath := k.Value
+			if path != "" {
+				kpath = path + "." + k.Value
+			}
+
+			f, ok := fields[k.Value]
+			if !ok {
+				return &UnknownFieldError{
+					Path:   kpath,
+					Field:  k.Value,
+					Line:   k.Line,
+					Column: k.Column,
+				}
+			}
+
+			if err := knownFields(v, f.Type, kpath); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			return nil
+		}
+
+		for i, c := range n.Content {
+			if err := knownFields(c, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// yamlFields returns the fields of struct type t indexed by their YAML keys.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		key, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if key == "-" || !f.IsExported() {
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(f.Name)
+		}
+
+		fields[key] = f
+	}
+
+	return fields
+}
+
+// hasKey reports whether mapping node n contains key.
+func hasKey(n *yaml.Node, key string) bool { return valueOf(n, key) != nil }
+
+// valueOf returns the value node for key in mapping node n, or nil if n is not
+// a mapping or does not contain key.
+func valueOf(n *yaml.Node, key string) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	// Mapping nodes store alternating keys and values.
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			v := n.Content[i+1]
+			if v.Kind == yaml.AliasNode {
+				v = v.Alias
+			}
+
+			return v
+		}
 	}
 
-	// Newlines become spaces, no leading or trailing whitespace.
-	*s = strings.TrimSpace(strings.ReplaceAll(*s, "\n", " "))
+	return nil
 }
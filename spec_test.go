@@ -1,10 +1,13 @@
 package yamlnetlink_test
 
 import (
+	"errors"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/mdlayher/yamlnetlink"
 )
 
@@ -14,18 +17,853 @@ func TestParse(t *testing.T) {
 		t.Fatalf("failed to parse nlctrl YAML: %v", err)
 	}
 
-	if diff := cmp.Diff(nlctrl(), s); diff != "" {
+	if diff := cmp.Diff(nlctrl(), s, cmpopts.IgnoreUnexported(yamlnetlink.Spec{})); diff != "" {
 		t.Fatalf("unexpected Spec (-want +got):\n%s", diff)
 	}
 }
 
+func TestParseDefinitions(t *testing.T) {
+	const in = `
+name: test
+definitions:
+  -
+    type: const
+    name: ALTIFNAMSIZ
+    value: 128
+    header: linux/if.h
+  -
+    type: enum
+    name: state
+    doc: |
+      Device state.
+    entries: [ down, up ]
+  -
+    type: enum
+    name: mode
+    name-prefix: test-mode-
+    value-start: 1
+    render-max: true
+    entries:
+      -
+        name: foo
+        doc: Foo mode.
+      - bar
+      -
+        name: baz
+        value: 10
+      - qux
+  -
+    type: flags
+    name: caps
+    entries: [ rx, tx ]
+  -
+    type: struct
+    name: stats
+    members:
+      -
+        name: packets
+        type: u64
+      -
+        name: ifname
+        type: binary
+        len: ALTIFNAMSIZ
+        doc: Interface name.
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	want := []yamlnetlink.Definition{
+		{
+			Name:   "ALTIFNAMSIZ",
+			Type:   yamlnetlink.DefinitionConst,
+			Header: "linux/if.h",
+			Value:  128,
+		},
+		{
+			Name: "state",
+			Type: yamlnetlink.DefinitionEnum,
+			Doc:  "Device state.\n",
+			Entries: []yamlnetlink.EnumEntry{
+				{Name: "down", Value: 0},
+				{Name: "up", Value: 1},
+			},
+		},
+		{
+			Name:       "mode",
+			Type:       yamlnetlink.DefinitionEnum,
+			NamePrefix: "test-mode-",
+			ValueStart: 1,
+			RenderMax:  true,
+			Entries: []yamlnetlink.EnumEntry{
+				{Name: "foo", Doc: "Foo mode.", Value: 1},
+				{Name: "bar", Value: 2},
+				{Name: "baz", Value: 10},
+				{Name: "qux", Value: 11},
+			},
+		},
+		{
+			Name: "caps",
+			Type: yamlnetlink.DefinitionFlags,
+			Entries: []yamlnetlink.EnumEntry{
+				{Name: "rx", Value: 0},
+				{Name: "tx", Value: 1},
+			},
+		},
+		{
+			Name: "stats",
+			Type: yamlnetlink.DefinitionStruct,
+			Members: []yamlnetlink.StructMember{
+				{Name: "packets", Type: "u64"},
+				{Name: "ifname", Type: "binary", Len: "ALTIFNAMSIZ", Doc: "Interface name."},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, s.Definitions); diff != "" {
+		t.Fatalf("unexpected Definitions (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseMulticastGroups(t *testing.T) {
+	f, err := os.Open("testdata/ethtool/ethtool.yaml")
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	s, err := yamlnetlink.Parse(f)
+	if err != nil {
+		t.Fatalf("failed to parse ethtool YAML: %v", err)
+	}
+
+	want := yamlnetlink.MulticastGroups{
+		List: []yamlnetlink.MulticastGroup{{
+			Name:        "monitor",
+			CDefineName: "ethtool-mcgrp-monitor-name",
+		}},
+	}
+
+	if diff := cmp.Diff(want, s.MulticastGroups); diff != "" {
+		t.Fatalf("unexpected MulticastGroups (-want +got):\n%s", diff)
+	}
+
+	groups := make(map[string]string)
+	for _, op := range s.Operations.List {
+		groups[op.Name] = op.MulticastGroup
+	}
+
+	if diff := cmp.Diff("monitor", groups["channels-ntf"]); diff != "" {
+		t.Fatalf("unexpected channels-ntf multicast group (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	type value struct {
+		Name         string
+		Value, Reply int
+	}
+
+	tests := []struct {
+		name  string
+		in    string
+		attrs []value
+		ops   []value
+		ok    bool
+	}{
+		{
+			name: "unified",
+			in: `
+name: test
+protocol: genetlink
+attribute-sets:
+  -
+    name: main
+    attr-cnt-name: __TEST_A_CNT
+    attributes:
+      - { name: unspec, type: unused, value: 0 }
+      - { name: foo, type: u32 }
+      - { name: bar, type: u32, value: 10 }
+      - { name: baz, type: u32 }
+operations:
+  list:
+    - { name: get, attribute-set: main }
+    - { name: set, attribute-set: main, value: 5 }
+    - { name: ntf, notify: get }
+`,
+			attrs: []value{{"unspec", 0, 0}, {"foo", 1, 0}, {"bar", 10, 0}, {"baz", 11, 0}},
+			ops:   []value{{"get", 1, 1}, {"set", 5, 5}, {"ntf", 6, 6}},
+			ok:    true,
+		},
+		{
+			name: "directional",
+			in: `
+name: test
+protocol: genetlink-legacy
+operations:
+  enum-model: directional
+  list:
+    -
+      name: strset-get
+      do:
+        request: { value: 1 }
+        reply: { value: 1 }
+    -
+      name: linkinfo-get
+      do:
+        request: {}
+        reply: {}
+    -
+      name: linkinfo-set
+      do:
+        request: {}
+    -
+      name: linkinfo-ntf
+      notify: linkinfo-get
+    -
+      name: channels-get
+      # Only notifications and events use an operation-level value.
+      value: 40
+      dump:
+        request: { value: 17 }
+        reply: { value: 17 }
+    -
+      name: channels-ntf
+      notify: channels-get
+    -
+      name: channels-set
+      do:
+        request: {}
+`,
+			ops: []value{
+				{"strset-get", 1, 1},
+				{"linkinfo-get", 2, 2},
+				{"linkinfo-set", 3, 0},
+				{"linkinfo-ntf", 0, 3},
+				{"channels-get", 17, 17},
+				{"channels-ntf", 0, 18},
+				{"channels-set", 18, 0},
+			},
+			ok: true,
+		},
+		{
+			// The numbering of upstream rt-link.yaml, in which notifications
+			// share the reply value of the command they notify about.
+			name: "directional rt-link",
+			in: `
+name: rt-link
+protocol: netlink-raw
+protonum: 0
+operations:
+  enum-model: directional
+  list:
+    -
+      name: newlink
+      do:
+        request: { value: 16 }
+    -
+      name: newlink-ntf
+      value: 16
+      notify: getlink
+    -
+      name: dellink
+      do:
+        request: { value: 17 }
+    -
+      name: getlink
+      do:
+        request: { value: 18 }
+        reply: { value: 16 }
+      dump:
+        request: {}
+        reply: {}
+    -
+      name: setlink
+      do:
+        request: { value: 19 }
+    -
+      name: getstats
+      do:
+        request: { value: 94 }
+        reply: { value: 92 }
+`,
+			ops: []value{
+				{"newlink", 16, 0},
+				{"newlink-ntf", 0, 16},
+				{"dellink", 17, 0},
+				{"getlink", 18, 16},
+				{"setlink", 19, 0},
+				{"getstats", 94, 92},
+			},
+			ok: true,
+		},
+		{
+			name: "expressions",
+			in: `
+name: test
+protocol: genetlink
+definitions:
+  - { name: base, type: const, value: 16 }
+  - { name: next, type: const, value: base + 4 }
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: foo, type: u32, value: base + 1 }
+      - { name: bar, type: u32 }
+      - { name: baz, type: u32, value: next * 2 }
+operations:
+  list:
+    - { name: get, attribute-set: main, value: next + 1 }
+    - { name: set, attribute-set: main }
+`,
+			attrs: []value{{"foo", 17, 0}, {"bar", 18, 0}, {"baz", 40, 0}},
+			ops:   []value{{"get", 21, 21}, {"set", 22, 22}},
+			ok:    true,
+		},
+		{
+			name: "unresolved value",
+			in: `
+name: test
+protocol: genetlink
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: foo, type: u32, value: FOO + 1 }
+`,
+		},
+		{
+			name: "directional genetlink",
+			in: `
+name: test
+protocol: genetlink
+operations:
+  enum-model: directional
+  list:
+    - { name: get, do: {} }
+`,
+		},
+		{
+			name: "unknown enum-model",
+			in: `
+name: test
+protocol: genetlink
+operations:
+  enum-model: sideways
+  list:
+    - { name: get, do: {} }
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := yamlnetlink.Parse(strings.NewReader(tt.in))
+			if tt.ok && err != nil {
+				t.Fatalf("failed to parse YAML: %v", err)
+			}
+			if !tt.ok {
+				if err == nil {
+					t.Fatal("expected an error, but none occurred")
+				}
+
+				return
+			}
+
+			var attrs, ops []value
+			for _, as := range s.AttributeSets {
+				for _, a := range as.Attributes {
+					attrs = append(attrs, value{Name: a.Name, Value: a.Value})
+				}
+			}
+			for _, op := range s.Operations.List {
+				ops = append(ops, value{op.Name, op.Value, op.ReplyValue})
+			}
+
+			if diff := cmp.Diff(tt.attrs, attrs); diff != "" {
+				t.Fatalf("unexpected attribute values (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.ops, ops); diff != "" {
+				t.Fatalf("unexpected operation values (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseOperations(t *testing.T) {
+	const in = `
+name: test
+protocol: genetlink-legacy
+operations:
+  enum-model: directional
+  name-prefix: test-msg-
+  async-prefix: test-ntf-
+  async-enum: test-ntf
+  list:
+    -
+      name: dev-set
+      attribute-set: dev
+      flags: [ admin-perm ]
+      config-cond: test-dev
+      do:
+        pre: test-pre-doit
+        post: test-post-doit
+        request:
+          attributes: [ ifindex ]
+    -
+      name: dev-add
+      attribute-set: dev
+      event:
+        attributes: [ ifindex ]
+      mcgrp: mgmt
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	want := yamlnetlink.Operations{
+		NamePrefix:  "test-msg-",
+		EnumModel:   yamlnetlink.EnumModelDirectional,
+		AsyncPrefix: "test-ntf-",
+		AsyncEnum:   "test-ntf",
+		List: []yamlnetlink.Operation{
+			{
+				Name:         "dev-set",
+				Value:        1,
+				AttributeSet: "dev",
+				Flags:        []yamlnetlink.OperationFlag{yamlnetlink.AdminPerm},
+				ConfigCond:   "test-dev",
+				Do: yamlnetlink.OperationAttributes{
+					Pre:  "test-pre-doit",
+					Post: "test-post-doit",
+					Request: yamlnetlink.OperationAttributesList{
+						Attributes: []string{"ifindex"},
+					},
+				},
+			},
+			{
+				Name:           "dev-add",
+				AttributeSet:   "dev",
+				MulticastGroup: "mgmt",
+				Event: yamlnetlink.OperationAttributesList{
+					Attributes: []string{"ifindex"},
+				},
+				ReplyValue: 1,
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, s.Operations); diff != "" {
+		t.Fatalf("unexpected Operations (-want +got):\n%s", diff)
+	}
+
+	var (
+		set = s.Operations.List[0]
+		add = s.Operations.List[1]
+	)
+
+	if !set.Privileged() || set.IsNotification() {
+		t.Fatalf("dev-set should be a privileged command: %+v", set)
+	}
+	if add.Privileged() || !add.IsNotification() {
+		t.Fatalf("dev-add should be an unprivileged event: %+v", add)
+	}
+}
+
+func TestParseChecks(t *testing.T) {
+	const in = `
+name: test
+attribute-sets:
+  -
+    name: main
+    attributes:
+      -
+        name: ifname
+        type: string
+        checks:
+          max-len: IFNAMSIZ - 1
+          unterminated-ok: true
+      -
+        name: addr
+        type: binary
+        checks:
+          exact-len: 6
+      -
+        name: key
+        type: binary
+        checks:
+          min-len: 16
+          max-len: 32
+      -
+        name: offset
+        type: s32
+        checks:
+          min: -128
+          max: 127
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	want := []yamlnetlink.Checks{
+		{MaxLen: "IFNAMSIZ - 1", UnterminatedOK: true},
+		{ExactLen: "6"},
+		{MinLen: "16", MaxLen: "32"},
+		{Min: "-128", Max: "127"},
+	}
+
+	var got []yamlnetlink.Checks
+	for _, a := range s.AttributeSets[0].Attributes {
+		got = append(got, a.Checks)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected Checks (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseEncodingHints(t *testing.T) {
+	const in = `
+name: test
+attribute-sets:
+  -
+    name: main
+    attributes:
+      -
+        name: port
+        type: u16
+        byte-order: big-endian
+      -
+        name: addr
+        type: binary
+        display-hint: ipv6
+      -
+        name: ports
+        type: array-nest
+        sub-type: u16
+        multi-attr: true
+      -
+        name: stats
+        type: binary
+        struct: stats
+      -
+        name: state
+        type: u32
+        enum: state
+      -
+        name: caps
+        type: u32
+        enum: caps
+        enum-as-flags: true
+        display-hint: hex
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	want := []yamlnetlink.Attribute{
+		{Name: "port", Type: "u16", Value: 1, ByteOrder: yamlnetlink.BigEndian},
+		{Name: "addr", Type: "binary", Value: 2, DisplayHint: yamlnetlink.IPv6},
+		{Name: "ports", Type: "array-nest", Value: 3, SubType: "u16", MultiAttr: true},
+		{Name: "stats", Type: "binary", Value: 4, Struct: "stats"},
+		{Name: "state", Type: "u32", Value: 5, Enum: "state"},
+		{
+			Name:        "caps",
+			Type:        "u32",
+			Value:       6,
+			Enum:        "caps",
+			EnumAsFlags: true,
+			DisplayHint: yamlnetlink.Hex,
+		},
+	}
+
+	if diff := cmp.Diff(want, s.AttributeSets[0].Attributes); diff != "" {
+		t.Fatalf("unexpected Attributes (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseEncodingHintsError(t *testing.T) {
+	tests := []struct {
+		name, in string
+	}{
+		{
+			name: "byte-order",
+			in: `
+attribute-sets:
+  - { name: main, attributes: [ { name: foo, type: u16, byte-order: middle-endian } ] }
+`,
+		},
+		{
+			name: "display-hint",
+			in: `
+attribute-sets:
+  - { name: main, attributes: [ { name: foo, type: binary, display-hint: emoji } ] }
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := yamlnetlink.Parse(strings.NewReader(tt.in)); err == nil {
+				t.Fatal("expected an error, but none occurred")
+			}
+		})
+	}
+}
+
+func TestParseNetlinkRaw(t *testing.T) {
+	const in = `
+name: test
+protocol: netlink-raw
+protonum: 12
+definitions:
+  -
+    name: ifinfomsg
+    type: struct
+    members:
+      - { name: ifi-family, type: u8 }
+      - { name: ifi-index, type: s32 }
+attribute-sets:
+  -
+    name: link-attrs
+    attributes:
+      - { name: linkinfo, type: nest, nested-attributes: linkinfo-attrs, value: 18 }
+  -
+    name: linkinfo-attrs
+    attributes:
+      - { name: kind, type: string }
+      - { name: data, type: sub-message, sub-message: linkinfo-data-msg, selector: kind }
+sub-messages:
+  -
+    name: linkinfo-data-msg
+    formats:
+      - { value: bridge, attribute-set: linkinfo-bridge-attrs }
+      - { value: tun, fixed-header: tun-hdr }
+operations:
+  fixed-header: ifinfomsg
+  list:
+    -
+      name: getlink
+      value: 18
+      attribute-set: link-attrs
+      do:
+        request:
+          attributes: [ linkinfo ]
+    -
+      name: getstats
+      value: 94
+      fixed-header: if-stats-msg
+mcast-groups:
+  list:
+    - { name: rtnlgrp-link, value: 1 }
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	if diff := cmp.Diff(12, s.Protonum); diff != "" {
+		t.Fatalf("unexpected protocol number (-want +got):\n%s", diff)
+	}
+
+	wantSub := []yamlnetlink.SubMessage{{
+		Name: "linkinfo-data-msg",
+		Formats: []yamlnetlink.SubMessageFormat{
+			{Value: "bridge", AttributeSet: "linkinfo-bridge-attrs"},
+			{Value: "tun", FixedHeader: "tun-hdr"},
+		},
+	}}
+
+	if diff := cmp.Diff(wantSub, s.SubMessages); diff != "" {
+		t.Fatalf("unexpected SubMessages (-want +got):\n%s", diff)
+	}
+
+	data := s.AttributeSets[1].Attributes[1]
+	if data.SubMessage != "linkinfo-data-msg" || data.Selector != "kind" {
+		t.Fatalf("unexpected sub-message attribute: %+v", data)
+	}
+
+	f, ok := s.SubMessages[0].Format("tun")
+	if !ok {
+		t.Fatal("failed to find tun sub-message format")
+	}
+	if diff := cmp.Diff("tun-hdr", f.FixedHeader); diff != "" {
+		t.Fatalf("unexpected tun fixed header (-want +got):\n%s", diff)
+	}
+	if _, ok := s.SubMessages[0].Format("vxlan"); ok {
+		t.Fatal("found unexpected vxlan sub-message format")
+	}
+
+	var headers []string
+	for _, op := range s.Operations.List {
+		headers = append(headers, op.FixedHeader)
+	}
+
+	if diff := cmp.Diff("ifinfomsg", s.Operations.FixedHeader); diff != "" {
+		t.Fatalf("unexpected default fixed header (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"", "if-stats-msg"}, headers); diff != "" {
+		t.Fatalf("unexpected operation fixed headers (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(1, s.MulticastGroups.List[0].Value); diff != "" {
+		t.Fatalf("unexpected multicast group value (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseSubsetOf(t *testing.T) {
+	const in = `
+name: test
+attribute-sets:
+  -
+    name: link
+    attributes:
+      -
+        name: ifindex
+        type: u32
+        doc: Interface index.
+      -
+        name: ifname
+        type: string
+        checks:
+          max-len: 15
+      -
+        name: stats
+        type: nest
+        nested-attributes: stats
+  -
+    name: link-dump
+    subset-of: link
+    attributes:
+      -
+        name: stats
+        nested-attributes: stats-brief
+      -
+        name: ifindex
+  -
+    name: link-id
+    subset-of: link-dump
+    attributes:
+      - name: ifindex
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	want := []yamlnetlink.AttributeSet{
+		{
+			Name:     "link-dump",
+			SubsetOf: "link",
+			Attributes: []yamlnetlink.Attribute{
+				{Name: "stats", Type: "nest", Value: 3, NestedAttributes: "stats-brief"},
+				{Name: "ifindex", Type: "u32", Value: 1, Doc: "Interface index."},
+			},
+		},
+		{
+			Name:     "link-id",
+			SubsetOf: "link-dump",
+			Attributes: []yamlnetlink.Attribute{
+				{Name: "ifindex", Type: "u32", Value: 1, Doc: "Interface index."},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, s.AttributeSets[1:]); diff != "" {
+		t.Fatalf("unexpected AttributeSets (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseSubsetOfCycle(t *testing.T) {
+	const in = `
+attribute-sets:
+  - { name: a, subset-of: b, attributes: [ { name: foo } ] }
+  - { name: b, subset-of: a, attributes: [ { name: foo } ] }
+`
+
+	if _, err := yamlnetlink.Parse(strings.NewReader(in)); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+}
+
+func TestParseWithOptionsStrict(t *testing.T) {
+	for _, family := range []string{"ethtool", "nlctrl"} {
+		t.Run(family, func(t *testing.T) {
+			f, err := os.Open("testdata/" + family + "/" + family + ".yaml")
+			if err != nil {
+				t.Fatalf("failed to open file: %v", err)
+			}
+			defer f.Close()
+
+			_, err = yamlnetlink.ParseWithOptions(f, &yamlnetlink.ParseOptions{Strict: true})
+			if err != nil {
+				t.Fatalf("failed to strictly parse YAML: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseWithOptionsStrictUnknownField(t *testing.T) {
+	const in = `
+name: test
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - &foo
+        name: foo
+        type: u32
+      -
+        <<: *foo
+        name: bar
+        frobnicate: true
+`
+
+	// Unknown fields are ignored by default.
+	if _, err := yamlnetlink.Parse(strings.NewReader(in)); err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	_, err := yamlnetlink.ParseWithOptions(
+		strings.NewReader(in),
+		&yamlnetlink.ParseOptions{Strict: true},
+	)
+
+	var ufe *yamlnetlink.UnknownFieldError
+	if !errors.As(err, &ufe) {
+		t.Fatalf("expected UnknownFieldError, but got: %v", err)
+	}
+
+	want := &yamlnetlink.UnknownFieldError{
+		Path:   "attribute-sets[0].attributes[1].frobnicate",
+		Field:  "frobnicate",
+		Line:   13,
+		Column: 9,
+	}
+
+	if diff := cmp.Diff(want, ufe); diff != "" {
+		t.Fatalf("unexpected UnknownFieldError (-want +got):\n%s", diff)
+	}
+}
+
 // nlctrl returns a well-formed YAML netlink Spec for the generic netlink nlctrl
 // family, for use in tests.
 func nlctrl() *yamlnetlink.Spec {
 	return &yamlnetlink.Spec{
 		Name:       "nlctrl",
 		Protocol:   "genetlink-legacy",
-		Doc:        "Generic netlink control protocol. Interface to query information about generic netlink families registered in the kernel - their names, ids, accepted messages and attributes.",
+		Doc:        "Generic netlink control protocol. Interface to query information about\ngeneric netlink families registered in the kernel - their names, ids,\naccepted messages and attributes.\n",
 		UAPIHeader: "linux/genetlink.h",
 		AttributeSets: []yamlnetlink.AttributeSet{
 			{
@@ -33,51 +871,61 @@ func nlctrl() *yamlnetlink.Spec {
 				NamePrefix: "ctrl-attr-",
 				Attributes: []yamlnetlink.Attribute{
 					{
-						Name: "family-id",
-						Type: "u16",
-						Doc:  "Numerical identifier of the family.",
+						Name:  "family-id",
+						Type:  "u16",
+						Value: 1,
+						Doc:   "Numerical identifier of the family.\n",
 					},
 					{
-						Name: "family-name",
-						Type: "nul-string",
-						Len:  "GENL_NAMSIZ - 1",
-						Doc:  "String identifier of the family. Guaranteed to be unique.",
+						Name:  "family-name",
+						Type:  "nul-string",
+						Value: 2,
+						Len:   "GENL_NAMSIZ - 1",
+						Doc:   "String identifier of the family. Guaranteed to be unique.\n",
 					},
 					{
-						Name: "version",
-						Type: "u32",
+						Name:  "version",
+						Type:  "u32",
+						Value: 3,
 					},
 					{
-						Name: "hdrsize",
-						Type: "u32",
+						Name:  "hdrsize",
+						Type:  "u32",
+						Value: 4,
 					},
 					{
-						Name: "maxattr",
-						Type: "u32",
+						Name:  "maxattr",
+						Type:  "u32",
+						Value: 5,
 					},
 					{
 						Name:             "ops",
 						Type:             "array-nest",
+						Value:            6,
 						NestedAttributes: "operation",
 					},
 					{
 						Name:             "mcast-groups",
 						Type:             "array-nest",
+						Value:            7,
 						NestedAttributes: "mcast-group",
 					},
 					{
-						Name: "op",
-						Type: "u32",
+						Name:  "op",
+						Type:  "u32",
+						Value: 8,
 					},
 					{
 						Name:             "op-policy",
 						Type:             "nest-type-value",
+						Value:            9,
 						TypeValue:        []string{"cmd"},
 						NestedAttributes: "policy",
 					},
 					{
 						Name:             "policy",
 						Type:             "nest-type-value",
+						Value:            10,
 						TypeValue:        []string{"current-policy-idx", "attr-idx"},
 						NestedAttributes: "nl-policy",
 					},
@@ -88,12 +936,14 @@ func nlctrl() *yamlnetlink.Spec {
 				NamePrefix: "ctrl-attr-op-",
 				Attributes: []yamlnetlink.Attribute{
 					{
-						Name: "id",
-						Type: "u32",
+						Name:  "id",
+						Type:  "u32",
+						Value: 1,
 					},
 					{
-						Name: "flags",
-						Type: "u32",
+						Name:  "flags",
+						Type:  "u32",
+						Value: 2,
 					},
 				},
 			},
@@ -102,12 +952,15 @@ func nlctrl() *yamlnetlink.Spec {
 				NamePrefix: "ctrl-attr-mcast-grp-",
 				Attributes: []yamlnetlink.Attribute{
 					{
-						Name: "id",
-						Type: "u32",
+						Name:  "id",
+						Type:  "u32",
+						Value: 1,
 					},
 					{
-						Name: "name",
-						Type: "nul-string", Len: "GENL_NAMSIZ - 1",
+						Name:  "name",
+						Type:  "nul-string",
+						Value: 2,
+						Len:   "GENL_NAMSIZ - 1",
 					},
 				},
 			},
@@ -116,12 +969,14 @@ func nlctrl() *yamlnetlink.Spec {
 				NamePrefix: "ctrl-attr-policy-",
 				Attributes: []yamlnetlink.Attribute{
 					{
-						Name: "do",
-						Type: "u32",
+						Name:  "do",
+						Type:  "u32",
+						Value: 1,
 					},
 					{
-						Name: "dump",
-						Type: "u32",
+						Name:  "dump",
+						Type:  "u32",
+						Value: 2,
 					},
 				},
 			},
@@ -130,48 +985,59 @@ func nlctrl() *yamlnetlink.Spec {
 				NamePrefix: "nl-policy-type-attr-",
 				Attributes: []yamlnetlink.Attribute{
 					{
-						Name: "type",
-						Type: "u32",
+						Name:  "type",
+						Type:  "u32",
+						Value: 1,
 					},
 					{
-						Name: "min-value-u",
-						Type: "u64",
+						Name:  "min-value-u",
+						Type:  "u64",
+						Value: 2,
 					},
 					{
-						Name: "max-value-u",
-						Type: "u64",
+						Name:  "max-value-u",
+						Type:  "u64",
+						Value: 3,
 					},
 					{
-						Name: "min-value-s",
-						Type: "s64",
+						Name:  "min-value-s",
+						Type:  "s64",
+						Value: 4,
 					},
 					{
-						Name: "max-value-s",
-						Type: "s64",
+						Name:  "max-value-s",
+						Type:  "s64",
+						Value: 5,
 					},
 					{
-						Name: "mask",
-						Type: "u64",
+						Name:  "mask",
+						Type:  "u64",
+						Value: 6,
 					},
 					{
-						Name: "min-length",
-						Type: "u32",
+						Name:  "min-length",
+						Type:  "u32",
+						Value: 7,
 					},
 					{
-						Name: "max-length",
-						Type: "u32",
+						Name:  "max-length",
+						Type:  "u32",
+						Value: 8,
 					},
 					{
-						Name: "policy-idx",
-						Type: "u32",
+						Name:  "policy-idx",
+						Type:  "u32",
+						Value: 9,
 					},
 					{
-						Name: "policy-maxtype",
-						Type: "u32",
+						Name:  "policy-maxtype",
+						Type:  "u32",
+						Value: 10,
 					},
 					{
-						Name: "bitfield32-mask",
-						Type: "u32",
+						Name:  "bitfield32-mask",
+						Type:  "u32",
+						Value: 11,
 					},
 				},
 			},
@@ -182,6 +1048,8 @@ func nlctrl() *yamlnetlink.Spec {
 				{
 					Name:         "getfamily",
 					Doc:          "Get information about genetlink family.",
+					Value:        1,
+					ReplyValue:   1,
 					AttributeSet: "main",
 					DontValidate: []string{"strict", "dump"},
 
@@ -204,28 +1072,38 @@ func nlctrl() *yamlnetlink.Spec {
 					},
 				},
 				{
-					Name:   "newfamily",
-					Doc:    "Notification for new families being registered.",
-					Notify: "getfamily",
+					Name:       "newfamily",
+					Doc:        "Notification for new families being registered.",
+					Value:      2,
+					ReplyValue: 2,
+					Notify:     "getfamily",
 				},
 				{
-					Name:   "delfamily",
-					Doc:    "Notification for families being unregistered.",
-					Notify: "getfamily",
+					Name:       "delfamily",
+					Doc:        "Notification for families being unregistered.",
+					Value:      3,
+					ReplyValue: 3,
+					Notify:     "getfamily",
 				},
 				{
-					Name:   "newmcast-grp",
-					Doc:    "Notification for new multicast groups.",
-					Notify: "getfamily",
+					Name:       "newmcast-grp",
+					Doc:        "Notification for new multicast groups.",
+					Value:      4,
+					ReplyValue: 4,
+					Notify:     "getfamily",
 				},
 				{
-					Name:   "delmcast-grp",
-					Doc:    "Notification for deleted multicast groups.",
-					Notify: "getfamily",
+					Name:       "delmcast-grp",
+					Doc:        "Notification for deleted multicast groups.",
+					Value:      5,
+					ReplyValue: 5,
+					Notify:     "getfamily",
 				},
 				{
 					Name:         "getpolicy",
 					Doc:          "Get attribute policy for a genetlink family.",
+					Value:        6,
+					ReplyValue:   6,
 					AttributeSet: "main",
 					Dump: yamlnetlink.OperationAttributes{
 						Request: yamlnetlink.OperationAttributesList{
@@ -0,0 +1,72 @@
+// Package specs bundles YAML netlink specifications so that they can be used
+// without vendoring YAML files:
+//
+//	s, err := specs.Load("ethtool")
+//
+// The bundled specifications are meant to be verbatim copies of the upstream
+// files in the Linux kernel's Documentation/netlink/specs directory at the
+// kernel tag named by the go:generate directive in this file, which go generate
+// records in Tag. Updating them requires network access:
+//
+//	$ go generate ./specs
+//
+// While Tag is empty, the bundled specifications have not yet been updated from
+// upstream: ethtool and nlctrl are bundled, trimmed to the subset of each
+// family which yamlnetlink can generate code for.
+//
+// The package's tests verify that every bundled specification parses,
+// validates, and generates Go code. yamlnetlink's own tests use separate
+// fixtures under testdata.
+package specs
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/mdlayher/yamlnetlink"
+)
+
+//go:generate go run update.go -tag v6.6
+
+// files contains the bundled specifications.
+//
+//go:embed *.yaml
+var files embed.FS
+
+// FS returns a filesystem containing the bundled YAML specification files at
+// its root.
+func FS() fs.FS { return files }
+
+var (
+	regOnce sync.Once
+	reg     *yamlnetlink.Registry
+	regErr  error
+)
+
+// Registry returns a yamlnetlink.Registry containing each bundled
+// specification. The Registry is parsed once and shared by all callers.
+func Registry() (*yamlnetlink.Registry, error) {
+	regOnce.Do(func() {
+		reg, regErr = yamlnetlink.LoadRegistry(files, ".", nil)
+	})
+
+	return reg, regErr
+}
+
+// Load returns a copy of the bundled Spec for family name. The caller may
+// modify the returned Spec without affecting other callers.
+func Load(name string) (*yamlnetlink.Spec, error) {
+	r, err := Registry()
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("specs: no bundled specification for family %q", name)
+	}
+
+	return s, nil
+}
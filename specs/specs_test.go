@@ -0,0 +1,63 @@
+package specs_test
+
+import (
+	"testing"
+
+	"github.com/mdlayher/yamlnetlink"
+	"github.com/mdlayher/yamlnetlink/specs"
+)
+
+func TestSpecs(t *testing.T) {
+	r, err := specs.Registry()
+	if err != nil {
+		t.Fatalf("failed to load bundled specs: %v", err)
+	}
+
+	names := r.Names()
+	if len(names) == 0 {
+		t.Fatal("no bundled specs")
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			s, err := specs.Load(name)
+			if err != nil {
+				t.Fatalf("failed to load spec: %v", err)
+			}
+
+			if err := s.Validate(); err != nil {
+				t.Fatalf("failed to validate spec: %v", err)
+			}
+
+			if _, err := yamlnetlink.Generate(s, nil); err != nil {
+				t.Fatalf("failed to generate code: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadNotFound(t *testing.T) {
+	if _, err := specs.Load("nonexistent"); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+}
+
+func TestLoadCopy(t *testing.T) {
+	s, err := specs.Load("nlctrl")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	// Modifications by one caller must not be visible to another.
+	s.Name = "modified"
+	s.AttributeSets[0].Attributes[0].Name = "modified"
+
+	s, err = specs.Load("nlctrl")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	if s.Name != "nlctrl" || s.AttributeSets[0].Attributes[0].Name == "modified" {
+		t.Fatal("bundled spec was modified by a previous caller")
+	}
+}
@@ -0,0 +1,6 @@
+package specs
+
+// Tag is the Linux kernel tag from which the bundled specifications were
+// copied. It is empty until the specifications are first updated from upstream
+// by go generate, which rewrites this file.
+const Tag = ""
@@ -0,0 +1,116 @@
+//go:build ignore
+
+// Command update replaces the bundled specifications with the YAML netlink
+// specifications from Documentation/netlink/specs in the Linux kernel at a
+// given tag, and records the tag in tag.go. It is run by go generate and
+// requires network access.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// contents is the GitHub API endpoint for a directory of the kernel mirror.
+const contents = "https://api.github.com/repos/torvalds/linux/contents/Documentation/netlink/specs?ref="
+
+func main() {
+	log.SetFlags(0)
+
+	tag := flag.String("tag", "", "required: the Linux kernel tag to copy specifications from, such as v6.6")
+	flag.Parse()
+
+	if *tag == "" {
+		log.Fatal("must specify a kernel tag:\n$ go run update.go -tag v6.6")
+	}
+
+	var files []struct {
+		Name        string `json:"name"`
+		Type        string `json:"type"`
+		DownloadURL string `json:"download_url"`
+	}
+
+	b, err := get(contents + *tag)
+	if err != nil {
+		log.Fatalf("failed to list specifications: %v", err)
+	}
+	if err := json.Unmarshal(b, &files); err != nil {
+		log.Fatalf("failed to decode specification list: %v", err)
+	}
+
+	// Remove the previous specifications so that files deleted upstream are
+	// not left behind.
+	old, err := filepath.Glob("*.yaml")
+	if err != nil {
+		log.Fatalf("failed to list bundled specifications: %v", err)
+	}
+	for _, f := range old {
+		if err := os.Remove(f); err != nil {
+			log.Fatalf("failed to remove bundled specification: %v", err)
+		}
+	}
+
+	var n int
+	for _, f := range files {
+		if f.Type != "file" || !strings.HasSuffix(f.Name, ".yaml") {
+			continue
+		}
+
+		b, err := get(f.DownloadURL)
+		if err != nil {
+			log.Fatalf("failed to download %s: %v", f.Name, err)
+		}
+
+		if err := os.WriteFile(f.Name, b, 0o644); err != nil {
+			log.Fatalf("failed to write %s: %v", f.Name, err)
+		}
+		n++
+	}
+	if n == 0 {
+		log.Fatalf("no specifications found at kernel tag %s", *tag)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `// Code generated by "go run update.go -tag %s"; DO NOT EDIT.
+
+package specs
+
+// Tag is the Linux kernel tag from which the bundled specifications were
+// copied.
+const Tag = %q
+`, *tag, *tag)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("failed to format tag.go: %v", err)
+	}
+	if err := os.WriteFile("tag.go", src, 0o644); err != nil {
+		log.Fatalf("failed to write tag.go: %v", err)
+	}
+
+	log.Printf("copied %d specifications from kernel tag %s", n, *tag)
+}
+
+// get returns the body of a successful HTTP GET request for url.
+func get(url string) ([]byte, error) {
+	res, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %s", res.Status)
+	}
+
+	return io.ReadAll(res.Body)
+}
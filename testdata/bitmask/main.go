@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func main() {
+	// The family does not exist in the kernel, so echo each request back as
+	// its reply.
+	c := &Conn{
+		c: genltest.Dial(func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return []genetlink.Message{greq}, nil
+		}),
+	}
+	defer c.Close()
+
+	var features Features
+	features.Set(FeaturesLow | FeaturesHigh | 1<<40)
+	features.Clear(FeaturesLow)
+
+	reply, err := c.DoEcho(DoEchoRequest{
+		Modes:    LinkModeFlags10BasetFull | LinkModeFlags100BasetFull,
+		Features: features,
+	})
+	if err != nil {
+		log.Fatalf("failed to echo: %v", err)
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(stdout{
+		Modes:       reply.Modes.String(),
+		Features:    reply.Features.String(),
+		Bits:        uint64(reply.Features),
+		HasHigh:     reply.Features.Has(FeaturesHigh),
+		HasLowHigh:  reply.Features.Has(FeaturesLow | FeaturesHigh),
+		NoModes:     LinkModeFlags(0).String(),
+		UnknownMode: (LinkModeFlags100BasetHalf | 1<<31).String(),
+	})
+}
+
+type stdout struct {
+	Modes       string `json:"modes"`
+	Features    string `json:"features"`
+	Bits        uint64 `json:"bits"`
+	HasHigh     bool   `json:"has_high"`
+	HasLowHigh  bool   `json:"has_low_high"`
+	NoModes     string `json:"no_modes"`
+	UnknownMode string `json:"unknown_mode"`
+}
@@ -1,16 +1,17 @@
 // Package main is generated from a YAML netlink specification for family "ethtool".
 //
-// Description: Ethernet device configuration interface.
+// Ethernet device configuration interface.
 //
 // Code generated by yamlnetlink-go. DO NOT EDIT.
 package main
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/mdlayher/genetlink"
 	"github.com/mdlayher/netlink"
-	"golang.org/x/sys/unix"
 )
 
 // A Conn is a connection to netlink family "ethtool".
@@ -38,19 +39,100 @@ func Dial(cfg *netlink.Config) (*Conn, error) {
 // Close closes the Conn's underlying netlink connection.
 func (c *Conn) Close() error { return c.c.Close() }
 
+// An Attr is a netlink attribute type for family "ethtool".
+type Attr uint16
+
+// Attribute types in attribute set "header".
+const (
+	EthtoolAHeaderDevIndex Attr = 1
+	EthtoolAHeaderDevName  Attr = 2
+	EthtoolAHeaderFlags    Attr = 3
+)
+
+// Attribute types in attribute set "channels".
+const (
+	EthtoolAChannelsHeader        Attr = 1
+	EthtoolAChannelsRxMax         Attr = 2
+	EthtoolAChannelsTxMax         Attr = 3
+	EthtoolAChannelsOtherMax      Attr = 4
+	EthtoolAChannelsCombinedMax   Attr = 5
+	EthtoolAChannelsRxCount       Attr = 6
+	EthtoolAChannelsTxCount       Attr = 7
+	EthtoolAChannelsOtherCount    Attr = 8
+	EthtoolAChannelsCombinedCount Attr = 9
+)
+
+// A Command is a generic netlink command for family "ethtool".
+type Command uint8
+
+// Commands.
+const (
+	EthtoolMsgChannelsGet      Command = 17
+	EthtoolMsgChannelsGetReply Command = 18
+	EthtoolMsgChannelsNtf      Command = 19
+	EthtoolMsgChannelsSet      Command = 18
+)
+
+// Multicast group names.
+const (
+	EthtoolMcgrpMonitorName = "monitor"
+)
+
+// A HeaderFlags is a bitmask of flags "header-flags".
+type HeaderFlags uint32
+
+// Possible HeaderFlags flags.
+const (
+	HeaderFlagsCompactBitsets HeaderFlags = 1 << 0
+	HeaderFlagsOmitReply      HeaderFlags = 1 << 1
+	HeaderFlagsStats          HeaderFlags = 1 << 2
+)
+
+// Has reports whether all of the flags in f are set in x.
+func (x HeaderFlags) Has(f HeaderFlags) bool { return x&f == f }
+
+// Set sets the flags in f.
+func (x *HeaderFlags) Set(f HeaderFlags) { *x |= f }
+
+// Clear clears the flags in f.
+func (x *HeaderFlags) Clear(f HeaderFlags) { *x &^= f }
+
+// String returns the names of the flags set in x, separated by |. Bits
+// which do not correspond to a flag are printed in hexadecimal.
+func (x HeaderFlags) String() string {
+	var names []string
+	if x&HeaderFlagsCompactBitsets != 0 {
+		names = append(names, "compact-bitsets")
+		x &^= HeaderFlagsCompactBitsets
+	}
+	if x&HeaderFlagsOmitReply != 0 {
+		names = append(names, "omit-reply")
+		x &^= HeaderFlagsOmitReply
+	}
+	if x&HeaderFlagsStats != 0 {
+		names = append(names, "stats")
+		x &^= HeaderFlagsStats
+	}
+	if x != 0 || len(names) == 0 {
+		names = append(names, fmt.Sprintf("%#x", uint32(x)))
+	}
+
+	return strings.Join(names, "|")
+}
+
 // DoChannelsGet wraps the "channels-get" operation:
 // Get current and max supported number of channels.
 func (c *Conn) DoChannelsGet(req DoChannelsGetRequest) (*DoChannelsGetReply, error) {
 	ae := netlink.NewAttributeEncoder()
-	ae.Nested(unix.ETHTOOL_A_CHANNELS_HEADER, func(ae *netlink.AttributeEncoder) error {
+	ae.Nested(uint16(EthtoolAChannelsHeader), func(ae *netlink.AttributeEncoder) error {
 		if req.Header.DevIndex != 0 {
-			ae.Uint32(unix.ETHTOOL_A_HEADER_DEV_INDEX, req.Header.DevIndex)
+			ae.Uint32(uint16(EthtoolAHeaderDevIndex), req.Header.DevIndex)
 		}
 		if req.Header.DevName != "" {
-			ae.String(unix.ETHTOOL_A_HEADER_DEV_NAME, req.Header.DevName)
+			ae.String(uint16(EthtoolAHeaderDevName), req.Header.DevName)
 		}
 		if req.Header.Flags != 0 {
-			ae.Uint32(unix.ETHTOOL_A_HEADER_FLAGS, req.Header.Flags)
+			ae.Uint32(uint16(EthtoolAHeaderFlags), uint32(req.Header.Flags))
 		}
 
 		return nil
@@ -63,7 +145,7 @@ func (c *Conn) DoChannelsGet(req DoChannelsGetRequest) (*DoChannelsGetReply, err
 
 	msg := genetlink.Message{
 		Header: genetlink.Header{
-			Command: unix.ETHTOOL_MSG_CHANNELS_GET,
+			Command: uint8(EthtoolMsgChannelsGet),
 			Version: c.f.Version,
 		},
 		Data: b,
@@ -83,37 +165,37 @@ func (c *Conn) DoChannelsGet(req DoChannelsGetRequest) (*DoChannelsGetReply, err
 
 		var reply DoChannelsGetReply
 		for ad.Next() {
-			switch ad.Type() {
-			case unix.ETHTOOL_A_CHANNELS_HEADER:
+			switch Attr(ad.Type()) {
+			case EthtoolAChannelsHeader:
 				ad.Nested(func(ad *netlink.AttributeDecoder) error {
 					for ad.Next() {
-						switch ad.Type() {
-						case unix.ETHTOOL_A_HEADER_DEV_INDEX:
+						switch Attr(ad.Type()) {
+						case EthtoolAHeaderDevIndex:
 							reply.Header.DevIndex = ad.Uint32()
-						case unix.ETHTOOL_A_HEADER_DEV_NAME:
+						case EthtoolAHeaderDevName:
 							reply.Header.DevName = ad.String()
-						case unix.ETHTOOL_A_HEADER_FLAGS:
-							reply.Header.Flags = ad.Uint32()
+						case EthtoolAHeaderFlags:
+							reply.Header.Flags = HeaderFlags(ad.Uint32())
 						}
 					}
 
 					return nil
 				})
-			case unix.ETHTOOL_A_CHANNELS_RX_MAX:
+			case EthtoolAChannelsRxMax:
 				reply.RxMax = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_TX_MAX:
+			case EthtoolAChannelsTxMax:
 				reply.TxMax = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_OTHER_MAX:
+			case EthtoolAChannelsOtherMax:
 				reply.OtherMax = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_COMBINED_MAX:
+			case EthtoolAChannelsCombinedMax:
 				reply.CombinedMax = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_RX_COUNT:
+			case EthtoolAChannelsRxCount:
 				reply.RxCount = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_TX_COUNT:
+			case EthtoolAChannelsTxCount:
 				reply.TxCount = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_OTHER_COUNT:
+			case EthtoolAChannelsOtherCount:
 				reply.OtherCount = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_COMBINED_COUNT:
+			case EthtoolAChannelsCombinedCount:
 				reply.CombinedCount = ad.Uint32()
 			}
 		}
@@ -140,7 +222,7 @@ func (c *Conn) DumpChannelsGet() ([]*DumpChannelsGetReply, error) {
 
 	msg := genetlink.Message{
 		Header: genetlink.Header{
-			Command: unix.ETHTOOL_MSG_CHANNELS_GET,
+			Command: uint8(EthtoolMsgChannelsGet),
 			Version: c.f.Version,
 		},
 		Data: b,
@@ -160,37 +242,37 @@ func (c *Conn) DumpChannelsGet() ([]*DumpChannelsGetReply, error) {
 
 		var reply DumpChannelsGetReply
 		for ad.Next() {
-			switch ad.Type() {
-			case unix.ETHTOOL_A_CHANNELS_HEADER:
+			switch Attr(ad.Type()) {
+			case EthtoolAChannelsHeader:
 				ad.Nested(func(ad *netlink.AttributeDecoder) error {
 					for ad.Next() {
-						switch ad.Type() {
-						case unix.ETHTOOL_A_HEADER_DEV_INDEX:
+						switch Attr(ad.Type()) {
+						case EthtoolAHeaderDevIndex:
 							reply.Header.DevIndex = ad.Uint32()
-						case unix.ETHTOOL_A_HEADER_DEV_NAME:
+						case EthtoolAHeaderDevName:
 							reply.Header.DevName = ad.String()
-						case unix.ETHTOOL_A_HEADER_FLAGS:
-							reply.Header.Flags = ad.Uint32()
+						case EthtoolAHeaderFlags:
+							reply.Header.Flags = HeaderFlags(ad.Uint32())
 						}
 					}
 
 					return nil
 				})
-			case unix.ETHTOOL_A_CHANNELS_RX_MAX:
+			case EthtoolAChannelsRxMax:
 				reply.RxMax = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_TX_MAX:
+			case EthtoolAChannelsTxMax:
 				reply.TxMax = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_OTHER_MAX:
+			case EthtoolAChannelsOtherMax:
 				reply.OtherMax = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_COMBINED_MAX:
+			case EthtoolAChannelsCombinedMax:
 				reply.CombinedMax = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_RX_COUNT:
+			case EthtoolAChannelsRxCount:
 				reply.RxCount = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_TX_COUNT:
+			case EthtoolAChannelsTxCount:
 				reply.TxCount = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_OTHER_COUNT:
+			case EthtoolAChannelsOtherCount:
 				reply.OtherCount = ad.Uint32()
-			case unix.ETHTOOL_A_CHANNELS_COMBINED_COUNT:
+			case EthtoolAChannelsCombinedCount:
 				reply.CombinedCount = ad.Uint32()
 			}
 		}
@@ -214,7 +296,7 @@ type DoChannelsGetRequest struct {
 type Header struct {
 	DevIndex uint32
 	DevName  string
-	Flags    uint32
+	Flags    HeaderFlags
 }
 
 // DoChannelsGetReply is used with the DoChannelsGet method.
@@ -247,30 +329,30 @@ type DumpChannelsGetReply struct {
 // Set number of channels.
 func (c *Conn) DoChannelsSet(req DoChannelsSetRequest) error {
 	ae := netlink.NewAttributeEncoder()
-	ae.Nested(unix.ETHTOOL_A_CHANNELS_HEADER, func(ae *netlink.AttributeEncoder) error {
+	ae.Nested(uint16(EthtoolAChannelsHeader), func(ae *netlink.AttributeEncoder) error {
 		if req.Header.DevIndex != 0 {
-			ae.Uint32(unix.ETHTOOL_A_HEADER_DEV_INDEX, req.Header.DevIndex)
+			ae.Uint32(uint16(EthtoolAHeaderDevIndex), req.Header.DevIndex)
 		}
 		if req.Header.DevName != "" {
-			ae.String(unix.ETHTOOL_A_HEADER_DEV_NAME, req.Header.DevName)
+			ae.String(uint16(EthtoolAHeaderDevName), req.Header.DevName)
 		}
 		if req.Header.Flags != 0 {
-			ae.Uint32(unix.ETHTOOL_A_HEADER_FLAGS, req.Header.Flags)
+			ae.Uint32(uint16(EthtoolAHeaderFlags), uint32(req.Header.Flags))
 		}
 
 		return nil
 	})
 	if req.RxCount != 0 {
-		ae.Uint32(unix.ETHTOOL_A_CHANNELS_RX_COUNT, req.RxCount)
+		ae.Uint32(uint16(EthtoolAChannelsRxCount), req.RxCount)
 	}
 	if req.TxCount != 0 {
-		ae.Uint32(unix.ETHTOOL_A_CHANNELS_TX_COUNT, req.TxCount)
+		ae.Uint32(uint16(EthtoolAChannelsTxCount), req.TxCount)
 	}
 	if req.OtherCount != 0 {
-		ae.Uint32(unix.ETHTOOL_A_CHANNELS_OTHER_COUNT, req.OtherCount)
+		ae.Uint32(uint16(EthtoolAChannelsOtherCount), req.OtherCount)
 	}
 	if req.CombinedCount != 0 {
-		ae.Uint32(unix.ETHTOOL_A_CHANNELS_COMBINED_COUNT, req.CombinedCount)
+		ae.Uint32(uint16(EthtoolAChannelsCombinedCount), req.CombinedCount)
 	}
 
 	b, err := ae.Encode()
@@ -280,7 +362,7 @@ func (c *Conn) DoChannelsSet(req DoChannelsSetRequest) error {
 
 	msg := genetlink.Message{
 		Header: genetlink.Header{
-			Command: unix.ETHTOOL_MSG_CHANNELS_SET,
+			Command: uint8(EthtoolMsgChannelsSet),
 			Version: c.f.Version,
 		},
 		Data: b,
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func main() {
+	// The family does not exist in the kernel, so echo each request back as
+	// its reply, along with the types of its attributes.
+	var types []uint16
+	c := &Conn{
+		c: genltest.Dial(func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			attrs, err := netlink.UnmarshalAttributes(greq.Data)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, a := range attrs {
+				types = append(types, a.Type)
+			}
+
+			return []genetlink.Message{greq}, nil
+		}),
+	}
+	defer c.Close()
+
+	reply, err := c.DoEcho(DoEchoRequest{Set: true})
+	if err != nil {
+		log.Fatalf("failed to echo: %v", err)
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(stdout{
+		Reply: *reply,
+		Types: types,
+	})
+}
+
+type stdout struct {
+	Reply DoEchoReply `json:"reply"`
+	Types []uint16    `json:"types"`
+}
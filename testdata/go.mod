@@ -5,7 +5,6 @@ go 1.19
 require (
 	github.com/mdlayher/genetlink v1.3.0
 	github.com/mdlayher/netlink v1.7.0
-	golang.org/x/sys v0.2.0
 )
 
 require (
@@ -14,4 +13,5 @@ require (
 	github.com/mdlayher/socket v0.4.0 // indirect
 	golang.org/x/net v0.2.0 // indirect
 	golang.org/x/sync v0.1.0 // indirect
+	golang.org/x/sys v0.2.0 // indirect
 )
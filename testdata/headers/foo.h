@@ -0,0 +1,42 @@
+/* SPDX-License-Identifier: GPL-2.0 WITH Linux-syscall-note */
+#ifndef _UAPI_LINUX_FOO_H
+#define _UAPI_LINUX_FOO_H
+
+#define FOO_GENL_NAME "foo" /* the "name" */
+#define FOO_GENL_VERSION 1
+
+enum {
+	FOO_A_HEADER_UNSPEC,
+	FOO_A_HEADER_DEV_INDEX,		/* u32 */
+	FOO_A_HEADER_DEV_NAME,		/* string */
+	FOO_A_HEADER_FLAGS,		// u32
+
+	/* add new constants above here */
+	__FOO_A_HEADER_CNT,
+	FOO_A_HEADER_MAX = (__FOO_A_HEADER_CNT-1)
+};
+
+enum {
+	FOO_A_CHANNELS_UNSPEC,
+	FOO_A_CHANNELS_HEADER,
+	FOO_A_CHANNELS_RX_MAX = 5,
+	FOO_A_CHANNELS_TX_MAX,
+
+	__FOO_A_CHANNELS_CNT,
+	FOO_A_CHANNELS_MAX = __FOO_A_CHANNELS_CNT - 1
+};
+
+enum foo_cmd {
+	FOO_CMD_UNSPEC,
+	FOO_CMD_CHANNELS_GET,
+	FOO_CMD_CHANNELS_SET,
+	__FOO_CMD_MAX,
+};
+#define FOO_CMD_MAX (__FOO_CMD_MAX - 1)
+
+enum foo_state {
+	FOO_STATE_DOWN,
+	FOO_STATE_UP = 0x4U,
+};
+
+#endif
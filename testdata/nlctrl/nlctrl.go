@@ -1,6 +1,8 @@
 // Package main is generated from a YAML netlink specification for family "nlctrl".
 //
-// Description: Generic netlink control protocol. Interface to query information about generic netlink families registered in the kernel - their names, ids, accepted messages and attributes.
+// Generic netlink control protocol. Interface to query information about
+// generic netlink families registered in the kernel - their names, ids,
+// accepted messages and attributes.
 //
 // Code generated by yamlnetlink-go. DO NOT EDIT.
 package main
@@ -10,7 +12,6 @@ import (
 
 	"github.com/mdlayher/genetlink"
 	"github.com/mdlayher/netlink"
-	"golang.org/x/sys/unix"
 )
 
 // A Conn is a connection to netlink family "nlctrl".
@@ -38,15 +39,78 @@ func Dial(cfg *netlink.Config) (*Conn, error) {
 // Close closes the Conn's underlying netlink connection.
 func (c *Conn) Close() error { return c.c.Close() }
 
+// An Attr is a netlink attribute type for family "nlctrl".
+type Attr uint16
+
+// Attribute types in attribute set "main".
+const (
+	CtrlAttrFamilyId    Attr = 1
+	CtrlAttrFamilyName  Attr = 2
+	CtrlAttrVersion     Attr = 3
+	CtrlAttrHdrsize     Attr = 4
+	CtrlAttrMaxattr     Attr = 5
+	CtrlAttrOps         Attr = 6
+	CtrlAttrMcastGroups Attr = 7
+	CtrlAttrOp          Attr = 10
+	CtrlAttrOpPolicy    Attr = 9
+	CtrlAttrPolicy      Attr = 8
+)
+
+// Attribute types in attribute set "operation".
+const (
+	CtrlAttrOpId    Attr = 1
+	CtrlAttrOpFlags Attr = 2
+)
+
+// Attribute types in attribute set "mcast-group".
+const (
+	CtrlAttrMcastGrpId   Attr = 2
+	CtrlAttrMcastGrpName Attr = 1
+)
+
+// Attribute types in attribute set "policy".
+const (
+	CtrlAttrPolicyDo   Attr = 1
+	CtrlAttrPolicyDump Attr = 2
+)
+
+// Attribute types in attribute set "nl-policy".
+const (
+	NlPolicyTypeAttrType           Attr = 1
+	NlPolicyTypeAttrMinValueU      Attr = 4
+	NlPolicyTypeAttrMaxValueU      Attr = 5
+	NlPolicyTypeAttrMinValueS      Attr = 2
+	NlPolicyTypeAttrMaxValueS      Attr = 3
+	NlPolicyTypeAttrMask           Attr = 12
+	NlPolicyTypeAttrMinLength      Attr = 6
+	NlPolicyTypeAttrMaxLength      Attr = 7
+	NlPolicyTypeAttrPolicyIdx      Attr = 8
+	NlPolicyTypeAttrPolicyMaxtype  Attr = 9
+	NlPolicyTypeAttrBitfield32Mask Attr = 10
+)
+
+// A Command is a generic netlink command for family "nlctrl".
+type Command uint8
+
+// Commands.
+const (
+	CtrlCmdGetfamily   Command = 3
+	CtrlCmdNewfamily   Command = 1
+	CtrlCmdDelfamily   Command = 2
+	CtrlCmdNewmcastGrp Command = 7
+	CtrlCmdDelmcastGrp Command = 8
+	CtrlCmdGetpolicy   Command = 10
+)
+
 // DoGetfamily wraps the "getfamily" operation:
 // Get information about genetlink family.
 func (c *Conn) DoGetfamily(req DoGetfamilyRequest) (*DoGetfamilyReply, error) {
 	ae := netlink.NewAttributeEncoder()
 	if req.FamilyId != 0 {
-		ae.Uint16(unix.CTRL_ATTR_FAMILY_ID, req.FamilyId)
+		ae.Uint16(uint16(CtrlAttrFamilyId), req.FamilyId)
 	}
 	if req.FamilyName != "" {
-		ae.String(unix.CTRL_ATTR_FAMILY_NAME, req.FamilyName)
+		ae.String(uint16(CtrlAttrFamilyName), req.FamilyName)
 	}
 
 	b, err := ae.Encode()
@@ -56,7 +120,7 @@ func (c *Conn) DoGetfamily(req DoGetfamilyRequest) (*DoGetfamilyReply, error) {
 
 	msg := genetlink.Message{
 		Header: genetlink.Header{
-			Command: unix.CTRL_CMD_GETFAMILY,
+			Command: uint8(CtrlCmdGetfamily),
 			Version: c.f.Version,
 		},
 		Data: b,
@@ -76,28 +140,28 @@ func (c *Conn) DoGetfamily(req DoGetfamilyRequest) (*DoGetfamilyReply, error) {
 
 		var reply DoGetfamilyReply
 		for ad.Next() {
-			switch ad.Type() {
-			case unix.CTRL_ATTR_FAMILY_ID:
+			switch Attr(ad.Type()) {
+			case CtrlAttrFamilyId:
 				reply.FamilyId = ad.Uint16()
-			case unix.CTRL_ATTR_FAMILY_NAME:
+			case CtrlAttrFamilyName:
 				reply.FamilyName = ad.String()
-			case unix.CTRL_ATTR_VERSION:
+			case CtrlAttrVersion:
 				reply.Version = ad.Uint32()
-			case unix.CTRL_ATTR_HDRSIZE:
+			case CtrlAttrHdrsize:
 				reply.Hdrsize = ad.Uint32()
-			case unix.CTRL_ATTR_MAXATTR:
+			case CtrlAttrMaxattr:
 				reply.Maxattr = ad.Uint32()
-			case unix.CTRL_ATTR_OPS:
+			case CtrlAttrOps:
 				ad.Nested(func(arr *netlink.AttributeDecoder) error {
 					reply.Ops = make([]Operation, 0, arr.Len())
 					for arr.Next() {
 						arr.Nested(func(ad *netlink.AttributeDecoder) error {
 							var nest Operation
 							for ad.Next() {
-								switch ad.Type() {
-								case unix.CTRL_ATTR_OP_ID:
+								switch Attr(ad.Type()) {
+								case CtrlAttrOpId:
 									nest.Id = ad.Uint32()
-								case unix.CTRL_ATTR_OP_FLAGS:
+								case CtrlAttrOpFlags:
 									nest.Flags = ad.Uint32()
 								}
 							}
@@ -110,17 +174,17 @@ func (c *Conn) DoGetfamily(req DoGetfamilyRequest) (*DoGetfamilyReply, error) {
 
 					return nil
 				})
-			case unix.CTRL_ATTR_MCAST_GROUPS:
+			case CtrlAttrMcastGroups:
 				ad.Nested(func(arr *netlink.AttributeDecoder) error {
 					reply.McastGroups = make([]McastGroup, 0, arr.Len())
 					for arr.Next() {
 						arr.Nested(func(ad *netlink.AttributeDecoder) error {
 							var nest McastGroup
 							for ad.Next() {
-								switch ad.Type() {
-								case unix.CTRL_ATTR_MCAST_GRP_ID:
+								switch Attr(ad.Type()) {
+								case CtrlAttrMcastGrpId:
 									nest.Id = ad.Uint32()
-								case unix.CTRL_ATTR_MCAST_GRP_NAME:
+								case CtrlAttrMcastGrpName:
 									nest.Name = ad.String()
 								}
 							}
@@ -158,7 +222,7 @@ func (c *Conn) DumpGetfamily() ([]*DumpGetfamilyReply, error) {
 
 	msg := genetlink.Message{
 		Header: genetlink.Header{
-			Command: unix.CTRL_CMD_GETFAMILY,
+			Command: uint8(CtrlCmdGetfamily),
 			Version: c.f.Version,
 		},
 		Data: b,
@@ -178,28 +242,28 @@ func (c *Conn) DumpGetfamily() ([]*DumpGetfamilyReply, error) {
 
 		var reply DumpGetfamilyReply
 		for ad.Next() {
-			switch ad.Type() {
-			case unix.CTRL_ATTR_FAMILY_ID:
+			switch Attr(ad.Type()) {
+			case CtrlAttrFamilyId:
 				reply.FamilyId = ad.Uint16()
-			case unix.CTRL_ATTR_FAMILY_NAME:
+			case CtrlAttrFamilyName:
 				reply.FamilyName = ad.String()
-			case unix.CTRL_ATTR_VERSION:
+			case CtrlAttrVersion:
 				reply.Version = ad.Uint32()
-			case unix.CTRL_ATTR_HDRSIZE:
+			case CtrlAttrHdrsize:
 				reply.Hdrsize = ad.Uint32()
-			case unix.CTRL_ATTR_MAXATTR:
+			case CtrlAttrMaxattr:
 				reply.Maxattr = ad.Uint32()
-			case unix.CTRL_ATTR_OPS:
+			case CtrlAttrOps:
 				ad.Nested(func(arr *netlink.AttributeDecoder) error {
 					reply.Ops = make([]Operation, 0, arr.Len())
 					for arr.Next() {
 						arr.Nested(func(ad *netlink.AttributeDecoder) error {
 							var nest Operation
 							for ad.Next() {
-								switch ad.Type() {
-								case unix.CTRL_ATTR_OP_ID:
+								switch Attr(ad.Type()) {
+								case CtrlAttrOpId:
 									nest.Id = ad.Uint32()
-								case unix.CTRL_ATTR_OP_FLAGS:
+								case CtrlAttrOpFlags:
 									nest.Flags = ad.Uint32()
 								}
 							}
@@ -212,17 +276,17 @@ func (c *Conn) DumpGetfamily() ([]*DumpGetfamilyReply, error) {
 
 					return nil
 				})
-			case unix.CTRL_ATTR_MCAST_GROUPS:
+			case CtrlAttrMcastGroups:
 				ad.Nested(func(arr *netlink.AttributeDecoder) error {
 					reply.McastGroups = make([]McastGroup, 0, arr.Len())
 					for arr.Next() {
 						arr.Nested(func(ad *netlink.AttributeDecoder) error {
 							var nest McastGroup
 							for ad.Next() {
-								switch ad.Type() {
-								case unix.CTRL_ATTR_MCAST_GRP_ID:
+								switch Attr(ad.Type()) {
+								case CtrlAttrMcastGrpId:
 									nest.Id = ad.Uint32()
-								case unix.CTRL_ATTR_MCAST_GRP_NAME:
+								case CtrlAttrMcastGrpName:
 									nest.Name = ad.String()
 								}
 							}
@@ -299,13 +363,13 @@ type DumpGetfamilyReply struct {
 func (c *Conn) DumpGetpolicy(req DumpGetpolicyRequest) ([]*DumpGetpolicyReply, error) {
 	ae := netlink.NewAttributeEncoder()
 	if req.FamilyId != 0 {
-		ae.Uint16(unix.CTRL_ATTR_FAMILY_ID, req.FamilyId)
+		ae.Uint16(uint16(CtrlAttrFamilyId), req.FamilyId)
 	}
 	if req.FamilyName != "" {
-		ae.String(unix.CTRL_ATTR_FAMILY_NAME, req.FamilyName)
+		ae.String(uint16(CtrlAttrFamilyName), req.FamilyName)
 	}
 	if req.Op != 0 {
-		ae.Uint32(unix.CTRL_ATTR_OP, req.Op)
+		ae.Uint32(uint16(CtrlAttrOp), req.Op)
 	}
 
 	b, err := ae.Encode()
@@ -315,7 +379,7 @@ func (c *Conn) DumpGetpolicy(req DumpGetpolicyRequest) ([]*DumpGetpolicyReply, e
 
 	msg := genetlink.Message{
 		Header: genetlink.Header{
-			Command: unix.CTRL_CMD_GETPOLICY,
+			Command: uint8(CtrlCmdGetpolicy),
 			Version: c.f.Version,
 		},
 		Data: b,
@@ -335,12 +399,12 @@ func (c *Conn) DumpGetpolicy(req DumpGetpolicyRequest) ([]*DumpGetpolicyReply, e
 
 		var reply DumpGetpolicyReply
 		for ad.Next() {
-			switch ad.Type() {
-			case unix.CTRL_ATTR_FAMILY_ID:
+			switch Attr(ad.Type()) {
+			case CtrlAttrFamilyId:
 				reply.FamilyId = ad.Uint16()
-			case unix.CTRL_ATTR_OP_POLICY:
+			case CtrlAttrOpPolicy:
 				// TODO: field "reply.OpPolicy", type "nest-type-value"
-			case unix.CTRL_ATTR_POLICY:
+			case CtrlAttrPolicy:
 				// TODO: field "reply.Policy", type "nest-type-value"
 			}
 		}
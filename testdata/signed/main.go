@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"os"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func main() {
+	// The family does not exist in the kernel, so echo each request back as
+	// its reply.
+	c := &Conn{
+		c: genltest.Dial(func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return []genetlink.Message{greq}, nil
+		}),
+	}
+	defer c.Close()
+
+	reply, err := c.DoEcho(DoEchoRequest{
+		S8:  math.MinInt8,
+		S16: -2,
+		S32: -3,
+		S64: math.MinInt64,
+	})
+	if err != nil {
+		log.Fatalf("failed to echo: %v", err)
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(reply)
+}
@@ -0,0 +1,585 @@
+package yamlnetlink
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A ValidationError describes a single semantic problem found in a Spec by
+// Validate.
+type ValidationError struct {
+	// Path is the location of the problem within the specification, such as
+	// "attribute-sets[0].attributes[1].nested-attributes".
+	Path string
+
+	// Line and Column are the position of Path in the YAML document. They are
+	// zero if the Spec was not produced by Parse.
+	Line, Column int
+
+	// Message describes the problem.
+	Message string
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("yamlnetlink: %s: %s", e.Path, e.Message)
+	}
+
+	return fmt.Sprintf("yamlnetlink: line %d, column %d: %s: %s",
+		e.Line, e.Column, e.Path, e.Message)
+}
+
+// ValidationErrors is a list of ValidationErrors returned by Validate.
+type ValidationErrors []*ValidationError
+
+// Error implements error.
+func (es ValidationErrors) Error() string {
+	ss := make([]string, 0, len(es))
+	for _, e := range es {
+		ss = append(ss, e.Error())
+	}
+
+	return strings.Join(ss, "\n")
+}
+
+// Validate checks a Spec for semantic problems such as references to attribute
+// sets, attributes, operations, or definitions which do not exist, duplicate
+// names or values, attributes without types, and operations without attribute
+// sets. If any problems are found, Validate returns all of them as
+// ValidationErrors. Names in expressions which cannot be resolved are reported
+// by Unresolved instead.
+func (s *Spec) Validate() error {
+	v := &validator{s: s}
+	v.definitions()
+	v.attributeSets()
+	v.operations()
+	v.multicastGroups()
+	v.subMessages()
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	return v.errs
+}
+
+// Unresolved reports each length or bound in the Spec which refers to names
+// that are neither const Definitions nor constants known to Eval, such as a
+// constant from a kernel header which the specification does not define.
+//
+// Unresolved names do not make a Spec invalid, so they are not reported by
+// Validate, and Resolve leaves the affected Limits unset. Callers may report
+// them as warnings.
+func (s *Spec) Unresolved() ValidationErrors {
+	v := &validator{s: s}
+	v.definitions()
+	v.attributeSets()
+	v.operations()
+	v.multicastGroups()
+	v.subMessages()
+
+	return v.unresolved
+}
+
+// A validator accumulates ValidationErrors for a Spec.
+type validator struct {
+	s          *Spec
+	errs       ValidationErrors
+	unresolved ValidationErrors
+}
+
+// errorf records a ValidationError at path.
+func (v *validator) errorf(path, format string, a ...any) {
+	v.errs = append(v.errs, v.newError(path, format, a...))
+}
+
+// newError creates a ValidationError at path.
+func (v *validator) newError(path, format string, a ...any) *ValidationError {
+	e := &ValidationError{
+		Path:    path,
+		Message: fmt.Sprintf(format, a...),
+	}
+
+	if n := lookup(v.s.node, path); n != nil {
+		e.Line, e.Column = n.Line, n.Column
+	}
+
+	return e
+}
+
+// unique records an error for each name or value which appears more than once
+// in a list. path formats the path of list item i.
+func (v *validator) unique(what string, items []string, path func(i int) string) {
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		if seen[item] {
+			v.errorf(path(i), "duplicate %s %s", what, item)
+		}
+		seen[item] = true
+	}
+}
+
+// definitions validates the Spec's Definitions.
+func (v *validator) definitions() {
+	names := make([]string, 0, len(v.s.Definitions))
+	for i, d := range v.s.Definitions {
+		path := fmt.Sprintf("definitions[%d]", i)
+		names = append(names, strconv.Quote(d.Name))
+
+		switch d.Type {
+		case DefinitionConst, DefinitionStruct:
+		case DefinitionEnum, DefinitionFlags:
+			var (
+				entries = make([]string, 0, len(d.Entries))
+				values  = make([]string, 0, len(d.Entries))
+			)
+
+			for j, e := range d.Entries {
+				entries = append(entries, strconv.Quote(e.Name))
+				values = append(values, strconv.Itoa(e.Value))
+
+				// Flags values are bit numbers in a 64-bit bitmask.
+				if d.Type == DefinitionFlags && !validBit(e.Value) {
+					v.errorf(fmt.Sprintf("%s.entries[%d]", path, j),
+						"flags entry %q has bit number %d, expected 0-63", e.Name, e.Value)
+				}
+			}
+
+			epath := func(i int) string { return fmt.Sprintf("%s.entries[%d]", path, i) }
+			v.unique("entry", entries, epath)
+			v.unique("value", values, epath)
+		case "":
+			v.errorf(path, "definition %q has no type", d.Name)
+		default:
+			v.errorf(path+".type", "definition %q has unknown type %q", d.Name, d.Type)
+		}
+
+		for j, m := range d.Members {
+			mpath := fmt.Sprintf("%s.members[%d]", path, j)
+			if m.Type == "" {
+				v.errorf(mpath, "member %q has no type", m.Name)
+			}
+
+			v.enumRef(mpath+".enum", m.Enum)
+			v.expr(mpath+".len", m.Len)
+		}
+	}
+
+	v.unique("definition", names, func(i int) string {
+		return fmt.Sprintf("definitions[%d].name", i)
+	})
+}
+
+// attributeSets validates the Spec's AttributeSets.
+func (v *validator) attributeSets() {
+	names := make([]string, 0, len(v.s.AttributeSets))
+	for i, as := range v.s.AttributeSets {
+		path := fmt.Sprintf("attribute-sets[%d]", i)
+		names = append(names, strconv.Quote(as.Name))
+
+		var parent *AttributeSet
+		if as.SubsetOf != "" && v.setRef(path+".subset-of", as.SubsetOf) {
+			parent = v.attributeSet(as.SubsetOf)
+		}
+
+		var (
+			attrs  = make([]string, 0, len(as.Attributes))
+			values = make([]string, 0, len(as.Attributes))
+		)
+
+		for j, a := range as.Attributes {
+			apath := fmt.Sprintf("%s.attributes[%d]", path, j)
+			attrs = append(attrs, strconv.Quote(a.Name))
+			values = append(values, strconv.Itoa(a.Value))
+
+			// The attributes of a subset take their types from the parent set.
+			switch {
+			case as.SubsetOf == "" && a.Type == "":
+				v.errorf(apath, "attribute %q has no type", a.Name)
+			case parent != nil:
+				if _, ok := parent.attribute(a.Name); !ok {
+					v.errorf(apath, "attribute %q is not present in parent set %q",
+						a.Name, parent.Name)
+				}
+			}
+
+			switch {
+			case a.NestedAttributes != "":
+				v.setRef(apath+".nested-attributes", a.NestedAttributes)
+			case a.Type == "nest" || a.Type == "array-nest":
+				v.errorf(apath, "%s attribute %q has no nested-attributes", a.Type, a.Name)
+			}
+
+			v.enumRef(apath+".enum", a.Enum)
+			if a.EnumAsFlags {
+				v.enumAsFlags(apath+".enum", a.Enum)
+			}
+			v.structRef(apath+".struct", a.Struct)
+			v.checks(apath, a)
+
+			if a.SubMessage != "" && !v.hasSubMessage(a.SubMessage) {
+				v.errorf(apath+".sub-message", "sub-message %q does not exist", a.SubMessage)
+			}
+			if a.Selector != "" {
+				if _, ok := as.attribute(a.Selector); !ok {
+					v.errorf(apath+".selector", "selector attribute %q does not exist in set %q",
+						a.Selector, as.Name)
+				}
+			}
+		}
+
+		apath := func(j int) string { return fmt.Sprintf("%s.attributes[%d]", path, j) }
+		v.unique("attribute", attrs, apath)
+
+		// Subsets share the values of their parent set, but may omit any of
+		// its attributes, so they need not be checked.
+		if as.SubsetOf == "" {
+			v.unique("value", values, apath)
+		}
+	}
+
+	v.unique("attribute set", names, func(i int) string {
+		return fmt.Sprintf("attribute-sets[%d].name", i)
+	})
+}
+
+// operations validates the Spec's Operations.
+func (v *validator) operations() {
+	ops := v.s.Operations
+
+	if ops.FixedHeader != "" {
+		v.structRef("operations.fixed-header", ops.FixedHeader)
+	}
+
+	var (
+		names    = make([]string, 0, len(ops.List))
+		requests = make([]string, 0, len(ops.List))
+		replies  = make([]string, 0, len(ops.List))
+	)
+
+	for i, op := range ops.List {
+		path := fmt.Sprintf("operations.list[%d]", i)
+		names = append(names, strconv.Quote(op.Name))
+
+		// Operations with no messages in a given direction have a zero value
+		// which must not be treated as a duplicate.
+		if op.Value != 0 {
+			requests = append(requests, strconv.Itoa(op.Value))
+		} else {
+			requests = append(requests, fmt.Sprintf("<none %d>", i))
+		}
+		// A notification shares the reply value of the operation it notifies
+		// about, as in rtnetlink.
+		switch {
+		case op.ReplyValue == 0:
+			replies = append(replies, fmt.Sprintf("<none %d>", i))
+		case op.Notify != "" && v.replyValue(op.Notify) == op.ReplyValue:
+			replies = append(replies, fmt.Sprintf("<notify %d>", i))
+		default:
+			replies = append(replies, strconv.Itoa(op.ReplyValue))
+		}
+
+		if op.Notify != "" && !v.hasOperation(op.Notify) {
+			v.errorf(path+".notify", "notify operation %q does not exist", op.Notify)
+		}
+		if op.MulticastGroup != "" && !v.hasMulticastGroup(op.MulticastGroup) {
+			v.errorf(path+".mcgrp", "multicast group %q does not exist", op.MulticastGroup)
+		}
+		if op.FixedHeader != "" {
+			v.structRef(path+".fixed-header", op.FixedHeader)
+		}
+
+		lists := []struct {
+			path string
+			list OperationAttributesList
+		}{
+			{path: path + ".do.request", list: op.Do.Request},
+			{path: path + ".do.reply", list: op.Do.Reply},
+			{path: path + ".dump.request", list: op.Dump.Request},
+			{path: path + ".dump.reply", list: op.Dump.Reply},
+			{path: path + ".event", list: op.Event},
+		}
+
+		var hasAttrs bool
+		for _, l := range lists {
+			if len(l.list.Attributes) > 0 {
+				hasAttrs = true
+			}
+		}
+
+		switch {
+		case op.AttributeSet == "":
+			if hasAttrs {
+				v.errorf(path, "operation %q has attributes but no attribute-set", op.Name)
+			}
+			continue
+		case !v.setRef(path+".attribute-set", op.AttributeSet):
+			continue
+		}
+
+		as := v.attributeSet(op.AttributeSet)
+		for _, l := range lists {
+			for j, a := range l.list.Attributes {
+				if _, ok := as.attribute(a); !ok {
+					v.errorf(fmt.Sprintf("%s.attributes[%d]", l.path, j),
+						"attribute %q does not exist in set %q", a, as.Name)
+				}
+			}
+		}
+	}
+
+	opath := func(i int) string { return fmt.Sprintf("operations.list[%d]", i) }
+	v.unique("operation", names, opath)
+
+	// In the unified model, the request and reply values are identical.
+	v.unique("value", requests, opath)
+	if ops.EnumModel == EnumModelDirectional {
+		v.unique("reply value", replies, opath)
+	}
+}
+
+// multicastGroups validates the Spec's MulticastGroups.
+func (v *validator) multicastGroups() {
+	names := make([]string, 0, len(v.s.MulticastGroups.List))
+	for _, g := range v.s.MulticastGroups.List {
+		names = append(names, strconv.Quote(g.Name))
+	}
+
+	v.unique("multicast group", names, func(i int) string {
+		return fmt.Sprintf("mcast-groups.list[%d].name", i)
+	})
+}
+
+// subMessages validates the Spec's SubMessages.
+func (v *validator) subMessages() {
+	names := make([]string, 0, len(v.s.SubMessages))
+	for i, sm := range v.s.SubMessages {
+		path := fmt.Sprintf("sub-messages[%d]", i)
+		names = append(names, strconv.Quote(sm.Name))
+
+		for j, f := range sm.Formats {
+			fpath := fmt.Sprintf("%s.formats[%d]", path, j)
+			if f.AttributeSet != "" {
+				v.setRef(fpath+".attribute-set", f.AttributeSet)
+			}
+			if f.FixedHeader != "" {
+				v.structRef(fpath+".fixed-header", f.FixedHeader)
+			}
+		}
+	}
+
+	v.unique("sub-message", names, func(i int) string {
+		return fmt.Sprintf("sub-messages[%d].name", i)
+	})
+}
+
+// setRef reports whether the named AttributeSet exists, recording an error at
+// path if not.
+func (v *validator) setRef(path, name string) bool {
+	if v.attributeSet(name) != nil {
+		return true
+	}
+
+	v.errorf(path, "attribute set %q does not exist", name)
+	return false
+}
+
+// enumRef records an error at path if name is set and does not refer to an
+// enum or flags Definition.
+func (v *validator) enumRef(path, name string) {
+	if name == "" {
+		return
+	}
+
+	d := v.definition(name)
+	switch {
+	case d == nil:
+		v.errorf(path, "enum %q does not exist", name)
+	case d.Type != DefinitionEnum && d.Type != DefinitionFlags:
+		v.errorf(path, "definition %q has type %s, expected enum or flags", name, d.Type)
+	}
+}
+
+// enumAsFlags records an error at path if the named enum Definition has a value
+// which cannot be used as a bit number in a 64-bit bitmask.
+func (v *validator) enumAsFlags(path, name string) {
+	d := v.definition(name)
+	if d == nil || d.Type != DefinitionEnum {
+		return
+	}
+
+	for _, e := range d.Entries {
+		if !validBit(e.Value) {
+			v.errorf(path, "enum %q entry %q has bit number %d, expected 0-63",
+				name, e.Name, e.Value)
+		}
+	}
+}
+
+// validBit reports whether n is a valid bit number in a 64-bit bitmask.
+func validBit(n int) bool { return n >= 0 && n < 64 }
+
+// checks records an error for each length or bound of Attribute a which is
+// not a valid expression.
+func (v *validator) checks(path string, a Attribute) {
+	v.expr(path+".len", a.Len)
+	v.expr(path+".checks.min", a.Checks.Min)
+	v.expr(path+".checks.max", a.Checks.Max)
+	v.expr(path+".checks.min-len", a.Checks.MinLen)
+	v.expr(path+".checks.max-len", a.Checks.MaxLen)
+	v.expr(path+".checks.exact-len", a.Checks.ExactLen)
+}
+
+// expr records an error at path if expr is set and cannot be evaluated. Names
+// which cannot be resolved are recorded separately.
+func (v *validator) expr(path, expr string) {
+	if expr == "" {
+		return
+	}
+
+	_, err := v.s.eval(expr)
+	if err == nil {
+		return
+	}
+
+	// Eval always returns *ExprError.
+	eerr := err.(*ExprError)
+	if len(eerr.Unresolved) > 0 {
+		v.unresolved = append(v.unresolved,
+			v.newError(path, "expression %q: %s", expr, eerr.message()))
+		return
+	}
+
+	v.errorf(path, "invalid expression %q: %s", expr, eerr.message())
+}
+
+// structRef records an error at path if name is set and does not refer to a
+// struct Definition.
+func (v *validator) structRef(path, name string) {
+	if name == "" {
+		return
+	}
+
+	d := v.definition(name)
+	switch {
+	case d == nil:
+		v.errorf(path, "struct %q does not exist", name)
+	case d.Type != DefinitionStruct:
+		v.errorf(path, "definition %q has type %s, expected struct", name, d.Type)
+	}
+}
+
+// attributeSet returns the named AttributeSet, or nil if it does not exist.
+func (v *validator) attributeSet(name string) *AttributeSet {
+	for i := range v.s.AttributeSets {
+		if v.s.AttributeSets[i].Name == name {
+			return &v.s.AttributeSets[i]
+		}
+	}
+
+	return nil
+}
+
+// definition returns the named Definition, or nil if it does not exist.
+func (v *validator) definition(name string) *Definition {
+	for i := range v.s.Definitions {
+		if v.s.Definitions[i].Name == name {
+			return &v.s.Definitions[i]
+		}
+	}
+
+	return nil
+}
+
+// hasOperation reports whether the named Operation exists.
+func (v *validator) hasOperation(name string) bool {
+	for _, op := range v.s.Operations.List {
+		if op.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// replyValue returns the ReplyValue of the named Operation, or 0 if it does
+// not exist.
+func (v *validator) replyValue(name string) int {
+	for _, op := range v.s.Operations.List {
+		if op.Name == name {
+			return op.ReplyValue
+		}
+	}
+
+	return 0
+}
+
+// hasMulticastGroup reports whether the named MulticastGroup exists.
+func (v *validator) hasMulticastGroup(name string) bool {
+	for _, g := range v.s.MulticastGroups.List {
+		if g.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasSubMessage reports whether the named SubMessage exists.
+func (v *validator) hasSubMessage(name string) bool {
+	for _, sm := range v.s.SubMessages {
+		if sm.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookup returns the YAML node at path within the document rooted at n, using
+// the same path syntax as ValidationError. If path cannot be fully resolved,
+// lookup returns the deepest node found, or nil if n is nil.
+func lookup(n *yaml.Node, path string) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	if path == "" {
+		return n
+	}
+
+	for _, seg := range strings.Split(path, ".") {
+		// Each segment is a mapping key followed by zero or more sequence
+		// indices, such as "attributes[1]".
+		key, rest, _ := strings.Cut(seg, "[")
+
+		next := valueOf(n, key)
+		if next == nil {
+			return n
+		}
+		n = next
+
+		for rest != "" {
+			idx, tail, _ := strings.Cut(rest, "]")
+			rest = strings.TrimPrefix(tail, "[")
+
+			i, err := strconv.Atoi(idx)
+			if err != nil || n.Kind != yaml.SequenceNode || i >= len(n.Content) {
+				return n
+			}
+
+			n = n.Content[i]
+			if n.Kind == yaml.AliasNode {
+				n = n.Alias
+			}
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,333 @@
+package yamlnetlink_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/yamlnetlink"
+)
+
+func TestSpecValidateOK(t *testing.T) {
+	for _, family := range []string{"ethtool", "nlctrl"} {
+		t.Run(family, func(t *testing.T) {
+			f, err := os.Open("testdata/" + family + "/" + family + ".yaml")
+			if err != nil {
+				t.Fatalf("failed to open file: %v", err)
+			}
+			defer f.Close()
+
+			s, err := yamlnetlink.Parse(f)
+			if err != nil {
+				t.Fatalf("failed to parse YAML: %v", err)
+			}
+
+			if err := s.Validate(); err != nil {
+				t.Fatalf("failed to validate spec: %v", err)
+			}
+		})
+	}
+}
+
+func TestSpecValidateErrors(t *testing.T) {
+	const in = `
+name: test
+definitions:
+  - { name: mode, type: enum, entries: [ a, b ] }
+  - { name: mode, type: const, value: 1 }
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: foo, type: u32 }
+      - { name: foo, type: u32 }
+      - { name: bar, value: 1, type: u32 }
+      - { name: nest, type: nest, nested-attributes: missing }
+      - { name: untyped }
+      - { name: hdr, type: binary, struct: mode }
+      - { name: empty, type: array-nest }
+operations:
+  list:
+    -
+      name: get
+      attribute-set: main
+      do:
+        request:
+          attributes: [ foo, baz ]
+    -
+      name: set
+      value: 1
+      do:
+        request:
+          attributes: [ foo ]
+    -
+      name: ntf
+      notify: got
+      mcgrp: monitor
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	var got yamlnetlink.ValidationErrors
+	if err := s.Validate(); !errors.As(err, &got) {
+		t.Fatalf("expected ValidationErrors, but got: %v", err)
+	}
+
+	want := yamlnetlink.ValidationErrors{
+		{
+			Path:    "definitions[1].name",
+			Line:    5,
+			Column:  13,
+			Message: `duplicate definition "mode"`,
+		},
+		{
+			Path:    "attribute-sets[0].attributes[3].nested-attributes",
+			Line:    13,
+			Column:  54,
+			Message: `attribute set "missing" does not exist`,
+		},
+		{
+			Path:    "attribute-sets[0].attributes[4]",
+			Line:    14,
+			Column:  9,
+			Message: `attribute "untyped" has no type`,
+		},
+		{
+			Path:    "attribute-sets[0].attributes[5].struct",
+			Line:    15,
+			Column:  44,
+			Message: `definition "mode" has type enum, expected struct`,
+		},
+		{
+			Path:    "attribute-sets[0].attributes[6]",
+			Line:    16,
+			Column:  9,
+			Message: `array-nest attribute "empty" has no nested-attributes`,
+		},
+		{
+			Path:    "attribute-sets[0].attributes[1]",
+			Line:    11,
+			Column:  9,
+			Message: `duplicate attribute "foo"`,
+		},
+		{
+			Path:    "attribute-sets[0].attributes[2]",
+			Line:    12,
+			Column:  9,
+			Message: "duplicate value 1",
+		},
+		{
+			Path:    "attribute-sets[0].attributes[3]",
+			Line:    13,
+			Column:  9,
+			Message: "duplicate value 2",
+		},
+		{
+			Path:    "operations.list[0].do.request.attributes[1]",
+			Line:    24,
+			Column:  30,
+			Message: `attribute "baz" does not exist in set "main"`,
+		},
+		{
+			Path:    "operations.list[1]",
+			Line:    26,
+			Column:  7,
+			Message: `operation "set" has attributes but no attribute-set`,
+		},
+		{
+			Path:    "operations.list[2].notify",
+			Line:    33,
+			Column:  15,
+			Message: `notify operation "got" does not exist`,
+		},
+		{
+			Path:    "operations.list[2].mcgrp",
+			Line:    34,
+			Column:  14,
+			Message: `multicast group "monitor" does not exist`,
+		},
+		{
+			Path:    "operations.list[1]",
+			Line:    26,
+			Column:  7,
+			Message: "duplicate value 1",
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected ValidationErrors (-want +got):\n%s", diff)
+	}
+}
+
+func TestSpecValidateSubsetOf(t *testing.T) {
+	const in = `
+name: test
+attribute-sets:
+  - { name: main, attributes: [ { name: foo, type: u32 } ] }
+  - { name: sub, subset-of: main, attributes: [ { name: bar } ] }
+  - { name: orphan, subset-of: missing, attributes: [ { name: foo } ] }
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	var got yamlnetlink.ValidationErrors
+	if err := s.Validate(); !errors.As(err, &got) {
+		t.Fatalf("expected ValidationErrors, but got: %v", err)
+	}
+
+	want := yamlnetlink.ValidationErrors{
+		{
+			Path:    "attribute-sets[1].attributes[0]",
+			Line:    5,
+			Column:  49,
+			Message: `attribute "bar" is not present in parent set "main"`,
+		},
+		{
+			Path:    "attribute-sets[2].subset-of",
+			Line:    6,
+			Column:  32,
+			Message: `attribute set "missing" does not exist`,
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected ValidationErrors (-want +got):\n%s", diff)
+	}
+}
+
+func TestSpecValidateBitmasks(t *testing.T) {
+	const in = `
+name: test
+definitions:
+  - { name: big, type: flags, entries: [ { name: a, value: 63 }, { name: b, value: 64 } ] }
+  - { name: mode, type: enum, entries: [ { name: c, value: 70 } ] }
+attribute-sets:
+  -
+    name: main
+    attributes:
+      - { name: modes, type: u64, enum: mode, enum-as-flags: true }
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	var got yamlnetlink.ValidationErrors
+	if err := s.Validate(); !errors.As(err, &got) {
+		t.Fatalf("expected ValidationErrors, but got: %v", err)
+	}
+
+	want := yamlnetlink.ValidationErrors{
+		{
+			Path:    "definitions[0].entries[1]",
+			Line:    4,
+			Column:  66,
+			Message: `flags entry "b" has bit number 64, expected 0-63`,
+		},
+		{
+			Path:    "attribute-sets[0].attributes[0].enum",
+			Line:    10,
+			Column:  41,
+			Message: `enum "mode" entry "c" has bit number 70, expected 0-63`,
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected ValidationErrors (-want +got):\n%s", diff)
+	}
+}
+
+func TestSpecValidateDirectionalReplies(t *testing.T) {
+	// The shape of upstream rt-link.yaml, in which a notification shares the
+	// reply value of the operation it notifies about.
+	const in = `
+name: rt-link
+protocol: netlink-raw
+protonum: 0
+operations:
+  enum-model: directional
+  list:
+    -
+      name: newlink
+      do:
+        request: { value: 16 }
+    -
+      name: newlink-ntf
+      value: 16
+      notify: getlink
+    -
+      name: getlink
+      do:
+        request: { value: 18 }
+        reply: { value: 16 }
+    -
+      name: getstats
+      do:
+        request: { value: 94 }
+        reply: { value: 92 }
+    -
+      name: stats-ntf
+      value: 16
+      notify: getstats
+`
+
+	s, err := yamlnetlink.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	var got yamlnetlink.ValidationErrors
+	if err := s.Validate(); !errors.As(err, &got) {
+		t.Fatalf("expected ValidationErrors, but got: %v", err)
+	}
+
+	// Only a notification which does not notify about the operation whose
+	// reply value it shares is a duplicate.
+	want := yamlnetlink.ValidationErrors{{
+		Path:    "operations.list[4]",
+		Line:    27,
+		Column:  7,
+		Message: "duplicate reply value 16",
+	}}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected ValidationErrors (-want +got):\n%s", diff)
+	}
+}
+
+func TestSpecValidateNoPositions(t *testing.T) {
+	s := &yamlnetlink.Spec{
+		Name: "test",
+		Operations: yamlnetlink.Operations{
+			List: []yamlnetlink.Operation{{
+				Name:         "get",
+				AttributeSet: "main",
+			}},
+		},
+	}
+
+	err := s.Validate()
+
+	want := yamlnetlink.ValidationErrors{{
+		Path:    "operations.list[0].attribute-set",
+		Message: `attribute set "main" does not exist`,
+	}}
+
+	if diff := cmp.Diff(want, err); diff != "" {
+		t.Fatalf("unexpected ValidationErrors (-want +got):\n%s", diff)
+	}
+
+	const msg = `yamlnetlink: operations.list[0].attribute-set: attribute set "main" does not exist`
+	if diff := cmp.Diff(msg, err.Error()); diff != "" {
+		t.Fatalf("unexpected error message (-want +got):\n%s", diff)
+	}
+}